@@ -0,0 +1,43 @@
+package accountclient
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+// AccountAPI describes all operations on account api provided by Client.
+// Code using this library can depend on AccountAPI instead of *Client to be able to replace it in tests,
+// i.e. with mock from accountmock package
+type AccountAPI interface {
+	// CreateAccount creates account, see Client.CreateAccount
+	CreateAccount(ctx context.Context, accountData *models.CreateAccountRequest, options ...CallOption) (*models.AccountResponse, error)
+	// CreateAccounts creates many accounts concurrently, see Client.CreateAccounts
+	CreateAccounts(ctx context.Context, requests []*models.CreateAccountRequest, batchOptions BatchOptions,
+		options ...CallOption) (*BatchResult, error)
+	// CreateAccountIdempotent creates account with id derived from external reference, see Client.CreateAccountIdempotent
+	CreateAccountIdempotent(ctx context.Context, externalRef string, accountData *models.CreateAccountRequest,
+		options ...CallOption) (*models.AccountResponse, error)
+	// FetchAccount fetches account, see Client.FetchAccount
+	FetchAccount(ctx context.Context, accountID uuid.UUID, options ...CallOption) (*models.AccountResponse, error)
+	// FetchAccounts fetches many accounts concurrently, see Client.FetchAccounts
+	FetchAccounts(ctx context.Context, ids []uuid.UUID, batchOptions BatchOptions, options ...CallOption,
+	) ([]*models.AccountResponse, map[uuid.UUID]error, error)
+	// DeleteAccount deletes account, see Client.DeleteAccount
+	DeleteAccount(ctx context.Context, accountID uuid.UUID, version *int64, options ...CallOption) error
+	// DeleteAccounts deletes many accounts concurrently, see Client.DeleteAccounts
+	DeleteAccounts(ctx context.Context, accounts []AccountVersion, batchOptions BatchOptions,
+		options ...CallOption) (*BatchResult, error)
+	// ListAccounts lists page of accounts, see Client.ListAccounts
+	ListAccounts(ctx context.Context, listOptions ListOptions, options ...CallOption) (*models.AccountsResponse, error)
+	// Health checks health of account api, see Client.Health
+	Health(ctx context.Context) (*Health, error)
+	// Endpoints returns health of endpoints, see Client.Endpoints
+	Endpoints() []EndpointStatus
+	// Close stops client gracefully, see Client.Close
+	Close(ctx context.Context) error
+}
+
+var _ AccountAPI = (*Client)(nil)
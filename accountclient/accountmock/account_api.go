@@ -0,0 +1,223 @@
+package accountmock
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient"
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+// Names of mocked methods, used in recorded Call
+const (
+	CreateAccountMethod           = "CreateAccount"
+	CreateAccountsMethod          = "CreateAccounts"
+	CreateAccountIdempotentMethod = "CreateAccountIdempotent"
+	FetchAccountMethod            = "FetchAccount"
+	FetchAccountsMethod           = "FetchAccounts"
+	DeleteAccountMethod           = "DeleteAccount"
+	DeleteAccountsMethod          = "DeleteAccounts"
+	ListAccountsMethod            = "ListAccounts"
+	HealthMethod                  = "Health"
+	EndpointsMethod               = "Endpoints"
+	CloseMethod                   = "Close"
+)
+
+// OnCreateAccount expects CreateAccount call with given accountData (or Any).
+// Expectation should return *models.AccountResponse and error
+func (m *Mock) OnCreateAccount(accountData interface{}) *Expectation {
+	return m.On(CreateAccountMethod, accountData)
+}
+
+// OnCreateAccounts expects CreateAccounts call with given requests and accountclient.BatchOptions (or Any).
+// Expectation should return *accountclient.BatchResult and error
+func (m *Mock) OnCreateAccounts(requests, batchOptions interface{}) *Expectation {
+	return m.On(CreateAccountsMethod, requests, batchOptions)
+}
+
+// OnCreateAccountIdempotent expects CreateAccountIdempotent call with given externalRef and accountData (or Any).
+// Expectation should return *models.AccountResponse and error
+func (m *Mock) OnCreateAccountIdempotent(externalRef, accountData interface{}) *Expectation {
+	return m.On(CreateAccountIdempotentMethod, externalRef, accountData)
+}
+
+// OnFetchAccount expects FetchAccount call with given accountID (or Any).
+// Expectation should return *models.AccountResponse and error
+func (m *Mock) OnFetchAccount(accountID interface{}) *Expectation {
+	return m.On(FetchAccountMethod, accountID)
+}
+
+// OnFetchAccounts expects FetchAccounts call with given ids and accountclient.BatchOptions (or Any).
+// Expectation should return []*models.AccountResponse, map[uuid.UUID]error and error
+func (m *Mock) OnFetchAccounts(ids, batchOptions interface{}) *Expectation {
+	return m.On(FetchAccountsMethod, ids, batchOptions)
+}
+
+// OnDeleteAccount expects DeleteAccount call with given accountID and version (or Any).
+// Version can be given as int64 or *int64, it is compared by value. Expectation should return error
+func (m *Mock) OnDeleteAccount(accountID, version interface{}) *Expectation {
+	if v, ok := version.(*int64); ok {
+		version = nil
+		if v != nil {
+			version = *v
+		}
+	}
+	return m.On(DeleteAccountMethod, accountID, version)
+}
+
+// OnDeleteAccounts expects DeleteAccounts call with given []accountclient.AccountVersion
+// and accountclient.BatchOptions (or Any). Expectation should return *accountclient.BatchResult and error
+func (m *Mock) OnDeleteAccounts(accounts, batchOptions interface{}) *Expectation {
+	return m.On(DeleteAccountsMethod, accounts, batchOptions)
+}
+
+// OnListAccounts expects ListAccounts call with given accountclient.ListOptions (or Any).
+// Expectation should return *models.AccountsResponse and error
+func (m *Mock) OnListAccounts(listOptions interface{}) *Expectation {
+	return m.On(ListAccountsMethod, listOptions)
+}
+
+// OnHealth expects Health call. Expectation should return *accountclient.Health and error
+func (m *Mock) OnHealth() *Expectation {
+	return m.On(HealthMethod)
+}
+
+// OnEndpoints expects Endpoints call. Expectation should return []accountclient.EndpointStatus
+func (m *Mock) OnEndpoints() *Expectation {
+	return m.On(EndpointsMethod)
+}
+
+// OnClose expects Close call. Expectation should return error
+func (m *Mock) OnClose() *Expectation {
+	return m.On(CloseMethod)
+}
+
+// CreateAccount records call and returns values from matching expectation
+func (m *Mock) CreateAccount(_ context.Context, accountData *models.CreateAccountRequest,
+	options ...accountclient.CallOption,
+) (*models.AccountResponse, error) {
+	returns, err := m.called(CreateAccountMethod, options, accountData)
+	if err != nil {
+		return nil, err
+	}
+	account, _ := returns.get(0).(*models.AccountResponse)
+	return account, returns.error(1)
+}
+
+// CreateAccounts records call and returns values from matching expectation
+func (m *Mock) CreateAccounts(_ context.Context, requests []*models.CreateAccountRequest,
+	batchOptions accountclient.BatchOptions, options ...accountclient.CallOption,
+) (*accountclient.BatchResult, error) {
+	returns, err := m.called(CreateAccountsMethod, options, requests, batchOptions)
+	if err != nil {
+		return nil, err
+	}
+	result, _ := returns.get(0).(*accountclient.BatchResult)
+	return result, returns.error(1)
+}
+
+// CreateAccountIdempotent records call and returns values from matching expectation
+func (m *Mock) CreateAccountIdempotent(_ context.Context, externalRef string, accountData *models.CreateAccountRequest,
+	options ...accountclient.CallOption,
+) (*models.AccountResponse, error) {
+	returns, err := m.called(CreateAccountIdempotentMethod, options, externalRef, accountData)
+	if err != nil {
+		return nil, err
+	}
+	account, _ := returns.get(0).(*models.AccountResponse)
+	return account, returns.error(1)
+}
+
+// FetchAccount records call and returns values from matching expectation
+func (m *Mock) FetchAccount(_ context.Context, accountID uuid.UUID,
+	options ...accountclient.CallOption,
+) (*models.AccountResponse, error) {
+	returns, err := m.called(FetchAccountMethod, options, accountID)
+	if err != nil {
+		return nil, err
+	}
+	account, _ := returns.get(0).(*models.AccountResponse)
+	return account, returns.error(1)
+}
+
+// FetchAccounts records call and returns values from matching expectation
+func (m *Mock) FetchAccounts(_ context.Context, ids []uuid.UUID, batchOptions accountclient.BatchOptions,
+	options ...accountclient.CallOption,
+) ([]*models.AccountResponse, map[uuid.UUID]error, error) {
+	returns, err := m.called(FetchAccountsMethod, options, ids, batchOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	accounts, _ := returns.get(0).([]*models.AccountResponse)
+	errs, _ := returns.get(1).(map[uuid.UUID]error)
+	return accounts, errs, returns.error(2)
+}
+
+// DeleteAccount records call and returns values from matching expectation. Version is recorded by value
+func (m *Mock) DeleteAccount(_ context.Context, accountID uuid.UUID, version *int64,
+	options ...accountclient.CallOption,
+) error {
+	var versionValue interface{}
+	if version != nil {
+		versionValue = *version
+	}
+	returns, err := m.called(DeleteAccountMethod, options, accountID, versionValue)
+	if err != nil {
+		return err
+	}
+	return returns.error(0)
+}
+
+// DeleteAccounts records call and returns values from matching expectation
+func (m *Mock) DeleteAccounts(_ context.Context, accounts []accountclient.AccountVersion,
+	batchOptions accountclient.BatchOptions, options ...accountclient.CallOption,
+) (*accountclient.BatchResult, error) {
+	returns, err := m.called(DeleteAccountsMethod, options, accounts, batchOptions)
+	if err != nil {
+		return nil, err
+	}
+	result, _ := returns.get(0).(*accountclient.BatchResult)
+	return result, returns.error(1)
+}
+
+// ListAccounts records call and returns values from matching expectation
+func (m *Mock) ListAccounts(_ context.Context, listOptions accountclient.ListOptions,
+	options ...accountclient.CallOption,
+) (*models.AccountsResponse, error) {
+	returns, err := m.called(ListAccountsMethod, options, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	accounts, _ := returns.get(0).(*models.AccountsResponse)
+	return accounts, returns.error(1)
+}
+
+// Health records call and returns values from matching expectation
+func (m *Mock) Health(_ context.Context) (*accountclient.Health, error) {
+	returns, err := m.called(HealthMethod, nil)
+	if err != nil {
+		return nil, err
+	}
+	health, _ := returns.get(0).(*accountclient.Health)
+	return health, returns.error(1)
+}
+
+// Endpoints records call and returns value from matching expectation, nil on unexpected call
+func (m *Mock) Endpoints() []accountclient.EndpointStatus {
+	returns, err := m.called(EndpointsMethod, nil)
+	if err != nil {
+		return nil
+	}
+	endpoints, _ := returns.get(0).([]accountclient.EndpointStatus)
+	return endpoints
+}
+
+// Close records call and returns value from matching expectation
+func (m *Mock) Close(_ context.Context) error {
+	returns, err := m.called(CloseMethod, nil)
+	if err != nil {
+		return err
+	}
+	return returns.error(0)
+}
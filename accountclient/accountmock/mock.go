@@ -0,0 +1,199 @@
+// Package accountmock provides mock of accountclient.AccountAPI for unit tests of code using accountclient package
+//
+// Mock records all calls and returns values configured with expectations:
+//
+//	m := accountmock.New()
+//	m.OnFetchAccount(accountID).Return(account, nil).Once()
+//	m.OnDeleteAccount(accountmock.Any, accountmock.Any).Return(nil)
+//
+//	service := NewService(m) // service depends on accountclient.AccountAPI
+//	...
+//	m.AssertExpectations(t)
+package accountmock
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient"
+)
+
+// ErrUnexpectedCall is returned by Mock methods called with arguments which don't match any expectation
+var ErrUnexpectedCall = errors.New("accountmock: unexpected call")
+
+// Any matches any argument value in expectation
+var Any = anyArgument{}
+
+type anyArgument struct{}
+
+// TestingT is a subset of testing.T used to report unmet expectations
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Call is a single recorded call of Mock method
+type Call struct {
+	// Method is name of called accountclient.AccountAPI method
+	Method string
+	// Args are method arguments without context and call options, in order of method parameters
+	Args []interface{}
+	// Options are call options passed to the method
+	Options []accountclient.CallOption
+}
+
+// Expectation describes expected call of Mock method and values it returns
+type Expectation struct {
+	method  string
+	args    []interface{}
+	returns []interface{}
+	// times is expected number of calls, 0 means that expectation can be called any number of times, but at least once
+	times int
+	calls int
+}
+
+// Return sets values returned by method matching the expectation, in order of method results
+func (e *Expectation) Return(values ...interface{}) *Expectation {
+	e.returns = values
+	return e
+}
+
+// Times sets how many times the expectation has to be met. After that it doesn't match further calls
+func (e *Expectation) Times(times int) *Expectation {
+	e.times = times
+	return e
+}
+
+// Once is the same as Times(1)
+func (e *Expectation) Once() *Expectation {
+	return e.Times(1)
+}
+
+func (e *Expectation) matches(method string, args []interface{}) bool {
+	if e.method != method || len(e.args) != len(args) {
+		return false
+	}
+	if e.times > 0 && e.calls >= e.times {
+		return false
+	}
+	for i, expected := range e.args {
+		if expected == Any {
+			continue
+		}
+		if !reflect.DeepEqual(expected, args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Expectation) String() string {
+	return fmt.Sprintf("%s%v", e.method, e.args)
+}
+
+// Mock is mock implementation of accountclient.AccountAPI. It is safe for concurrent use
+type Mock struct {
+	mu              sync.Mutex
+	expectations    []*Expectation
+	calls           []Call
+	unexpectedCalls []Call
+}
+
+var _ accountclient.AccountAPI = (*Mock)(nil)
+
+// New creates Mock without any expectations
+func New() *Mock {
+	return &Mock{}
+}
+
+// Calls returns all recorded calls in order they have been made
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsOf returns recorded calls of given method
+func (m *Mock) CallsOf(method string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	calls := make([]Call, 0)
+	for _, call := range m.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// AssertExpectations reports with t all expectations which haven't been met and all unexpected calls.
+// It returns true when all expectations have been met
+func (m *Mock) AssertExpectations(t TestingT) bool {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ok := true
+	for _, expectation := range m.expectations {
+		switch {
+		case expectation.times == 0 && expectation.calls == 0:
+			t.Errorf("accountmock: expected call %s hasn't been made", expectation)
+			ok = false
+		case expectation.times > 0 && expectation.calls != expectation.times:
+			t.Errorf("accountmock: expected call %s %d times, but it has been made %d times",
+				expectation, expectation.times, expectation.calls)
+			ok = false
+		}
+	}
+	for _, call := range m.unexpectedCalls {
+		t.Errorf("accountmock: unexpected call %s%v", call.Method, call.Args)
+		ok = false
+	}
+	return ok
+}
+
+// On adds expectation of call of method with given arguments. Arguments are given without context and call options.
+// Typed helpers like OnFetchAccount should be preferred
+func (m *Mock) On(method string, args ...interface{}) *Expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expectation := &Expectation{method: method, args: args}
+	m.expectations = append(m.expectations, expectation)
+	return expectation
+}
+
+// called records call and returns values of the first matching expectation
+func (m *Mock) called(method string, options []accountclient.CallOption, args ...interface{}) (returnValues, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	call := Call{Method: method, Args: args, Options: options}
+	m.calls = append(m.calls, call)
+	for _, expectation := range m.expectations {
+		if expectation.matches(method, args) {
+			expectation.calls++
+			return expectation.returns, nil
+		}
+	}
+
+	m.unexpectedCalls = append(m.unexpectedCalls, call)
+	return nil, fmt.Errorf("%w: %s%v", ErrUnexpectedCall, method, args)
+}
+
+type returnValues []interface{}
+
+func (r returnValues) get(i int) interface{} {
+	if i >= len(r) {
+		return nil
+	}
+	return r[i]
+}
+
+func (r returnValues) error(i int) error {
+	err, _ := r.get(i).(error)
+	return err
+}
@@ -0,0 +1,153 @@
+package accountmock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient"
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+type accountMockSuite struct {
+	suite.Suite
+}
+
+func TestAccountMock(t *testing.T) {
+	suite.Run(t, &accountMockSuite{})
+}
+
+func (s *accountMockSuite) TestExpectations() {
+	s.Run("should return values of matching expectations and record calls", func() {
+		// given
+		m := New()
+		accountID := uuid.New()
+		account := &models.AccountResponse{Data: &models.AccountDataResponse{ID: accountID}}
+		deleteErr := errors.New("delete failed")
+		m.OnFetchAccount(accountID).Return(account, nil).Once()
+		m.OnDeleteAccount(Any, int64(1)).Return(deleteErr)
+		var api accountclient.AccountAPI = m
+
+		// when
+		fetchedAccount, fetchErr := api.FetchAccount(context.Background(), accountID, accountclient.WithRequestID("id"))
+		version := int64(1)
+		err := api.DeleteAccount(context.Background(), accountID, &version)
+
+		// then
+		s.Assert().NoError(fetchErr)
+		s.Assert().Equal(account, fetchedAccount)
+		s.Assert().ErrorIs(err, deleteErr)
+		s.Require().Len(m.Calls(), 2)
+		s.Assert().Equal([]interface{}{accountID}, m.CallsOf(FetchAccountMethod)[0].Args)
+		s.Assert().Len(m.CallsOf(FetchAccountMethod)[0].Options, 1)
+		s.Assert().Equal([]interface{}{accountID, int64(1)}, m.CallsOf(DeleteAccountMethod)[0].Args)
+		s.Assert().True(m.AssertExpectations(s.T()))
+	})
+
+	s.Run("should return error on unexpected call and report it", func() {
+		// given
+		m := New()
+		m.OnFetchAccount(uuid.New()).Return(nil, nil).Once()
+		t := &recordingT{}
+
+		// when
+		_, err := m.FetchAccount(context.Background(), uuid.New())
+
+		// then
+		s.Assert().ErrorIs(err, ErrUnexpectedCall)
+		s.Assert().False(m.AssertExpectations(t))
+		s.Assert().Len(t.errors, 2)
+	})
+
+	s.Run("should not match expectation after it has been met given number of times", func() {
+		// given
+		m := New()
+		m.OnCreateAccount(Any).Return(&models.AccountResponse{}, nil).Times(2)
+		t := &recordingT{}
+
+		// when
+		errs := make([]error, 0)
+		for i := 0; i < 3; i++ {
+			_, err := m.CreateAccount(context.Background(), &models.CreateAccountRequest{})
+			errs = append(errs, err)
+		}
+
+		// then
+		s.Assert().NoError(errs[0])
+		s.Assert().NoError(errs[1])
+		s.Assert().ErrorIs(errs[2], ErrUnexpectedCall)
+		s.Assert().False(m.AssertExpectations(t))
+		s.Assert().Len(t.errors, 1)
+	})
+}
+
+func (s *accountMockSuite) TestLifecycle() {
+	s.Run("should return endpoints and close error of expectations", func() {
+		// given
+		m := New()
+		endpoints := []accountclient.EndpointStatus{{URL: "http://localhost:8080", Healthy: true}}
+		closeErr := errors.New("in-flight requests haven't finished")
+		m.OnEndpoints().Return(endpoints)
+		m.OnClose().Return(closeErr)
+		var api accountclient.AccountAPI = m
+
+		// when
+		returnedEndpoints := api.Endpoints()
+		err := api.Close(context.Background())
+
+		// then
+		s.Assert().Equal(endpoints, returnedEndpoints)
+		s.Assert().ErrorIs(err, closeErr)
+		s.Assert().True(m.AssertExpectations(s.T()))
+	})
+}
+
+func (s *accountMockSuite) TestHealthHandler() {
+	s.Run("should respond with health returned by mock", func() {
+		// given
+		m := New()
+		m.OnHealth().Return(&accountclient.Health{Status: accountclient.HealthStatusUp, CircuitOpen: true}, nil)
+		handler := accountclient.HealthHandler(m, time.Second)
+		recorder := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", http.NoBody))
+
+		// then
+		s.Assert().Equal(http.StatusServiceUnavailable, recorder.Code)
+		s.Assert().Contains(recorder.Body.String(), `"circuit_open":true`)
+		s.Assert().True(m.AssertExpectations(s.T()))
+	})
+
+	s.Run("should respond with service unavailable when health check fails", func() {
+		// given
+		m := New()
+		m.OnHealth().Return(nil, errors.New("connection refused"))
+		handler := accountclient.HealthHandler(m, time.Second)
+		recorder := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", http.NoBody))
+
+		// then
+		s.Assert().Equal(http.StatusServiceUnavailable, recorder.Code)
+		s.Assert().Contains(recorder.Body.String(), "connection refused")
+	})
+}
@@ -0,0 +1,147 @@
+package accounttest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Fault describes misbehaviour of Server. Faults are injected with Server.InjectFault and applied to incoming
+// requests in order of injection. Each Fault is applied to limited number of requests, after that it is removed
+type Fault struct {
+	// method limits fault to requests with given http method, empty matches all methods
+	method string
+	// times is how many requests fault is applied to, non-positive value means all requests until faults are cleared
+	times int
+	// apply writes response instead of regular handler. When it returns false, request is handled by regular handler
+	apply func(w http.ResponseWriter, r *http.Request) bool
+}
+
+// WithMethod returns copy of Fault which is applied only to requests with given http method
+func (f Fault) WithMethod(method string) Fault {
+	f.method = method
+	return f
+}
+
+// StatusFault responds to next times requests with given status code and error_message body
+func StatusFault(statusCode, times int) Fault {
+	return Fault{times: times, apply: func(w http.ResponseWriter, _ *http.Request) bool {
+		writeError(w, statusCode, http.StatusText(statusCode))
+		return true
+	}}
+}
+
+// DelayFault delays next times requests, after the delay requests are handled as usual.
+// Delay is interrupted when client cancels request
+func DelayFault(delay time.Duration, times int) Fault {
+	return Fault{times: times, apply: func(_ http.ResponseWriter, r *http.Request) bool {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+		}
+		return false
+	}}
+}
+
+// DropConnectionFault responds to next times requests with status 200 and part of the body,
+// and then closes connection before whole declared body is sent
+func DropConnectionFault(times int) Fault {
+	return Fault{times: times, apply: func(w http.ResponseWriter, _ *http.Request) bool {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			writeError(w, http.StatusInternalServerError, "connection can't be dropped")
+			return true
+		}
+		conn, buf, err := hijacker.Hijack()
+		if err != nil {
+			return true
+		}
+		defer conn.Close()
+		_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: application/vnd.api+json\r\nContent-Length: 1024\r\n\r\n{\"data\":{")
+		_ = buf.Flush()
+		return true
+	}}
+}
+
+// MalformedJSONFault responds to next times requests with status 200 and body which is not a valid json
+func MalformedJSONFault(times int) Fault {
+	return Fault{times: times, apply: func(w http.ResponseWriter, _ *http.Request) bool {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"data":{"id":`))
+		return true
+	}}
+}
+
+// NonJSONFault responds to next times requests with given status code and plain text body,
+// in the same way as api gateway responds i.e. with 403
+func NonJSONFault(statusCode int, body string, times int) Fault {
+	return Fault{times: times, apply: func(w http.ResponseWriter, _ *http.Request) bool {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(body))
+		return true
+	}}
+}
+
+// RateLimitFault responds to next times requests with 429 Too Many Requests and Retry-After header
+// with retryAfter rounded up to full seconds
+func RateLimitFault(retryAfter time.Duration, times int) Fault {
+	seconds := int((retryAfter + time.Second - 1) / time.Second)
+	return Fault{times: times, apply: func(w http.ResponseWriter, _ *http.Request) bool {
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return true
+	}}
+}
+
+// InjectFault adds fault applied to next matching requests after already injected faults are used up
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// ClearFaults removes all injected faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// RequestCount returns number of requests received by Server, including requests affected by faults
+func (s *Server) RequestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requestCount
+}
+
+func (s *Server) withFaults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fault := s.nextFault(r.Method)
+		if fault != nil && fault.apply(w, r) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) nextFault(method string) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requestCount++
+	for i, fault := range s.faults {
+		if fault.method != "" && fault.method != method {
+			continue
+		}
+		if fault.times > 0 {
+			fault.times--
+			if fault.times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return fault
+	}
+	return nil
+}
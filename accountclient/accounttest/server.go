@@ -0,0 +1,445 @@
+// Package accounttest provides in-process fake of form3 account api for tests
+//
+// Server implements the same subset of account api as fake api delivered with docker-compose.yml:
+// creating, fetching, listing and deleting accounts under /v1/organisation/accounts and health check under /v1/health.
+// Validation messages, version checks, status codes and error_message format follow fake api, so tests of code using
+// accountclient package can be run with plain go test without docker.
+//
+// Server can also misbehave in a scripted way with injected Fault, which allows to test retries,
+// backoff and circuit breaker deterministically
+package accounttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+const (
+	accountsPath    = "/v1/organisation/accounts"
+	healthPath      = "/v1/health"
+	accountType     = "accounts"
+	defaultPageSize = 100
+	validationList  = "validation failure list:"
+)
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// Server is fake account api running on local loopback interface. It must be closed with Close when it's not needed
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	accounts map[uuid.UUID]*models.AccountDataResponse
+	// order in which accounts have been created, used to return stable pages on listing
+	order []uuid.UUID
+	now   func() time.Time
+
+	faults       []*Fault
+	requestCount int
+}
+
+type responseLinks struct {
+	First string `json:"first,omitempty"`
+	Last  string `json:"last,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Self  string `json:"self"`
+}
+
+type accountResponse struct {
+	Data  *models.AccountDataResponse `json:"data"`
+	Links responseLinks               `json:"links"`
+}
+
+type accountsResponse struct {
+	Data  []*models.AccountDataResponse `json:"data"`
+	Links responseLinks                 `json:"links"`
+}
+
+type errorResponse struct {
+	ErrorMessage string `json:"error_message"`
+}
+
+// NewServer starts and returns a new Server
+func NewServer() *Server {
+	s := &Server{
+		accounts: make(map[uuid.UUID]*models.AccountDataResponse),
+		now:      time.Now,
+	}
+	s.Server = httptest.NewServer(s.withFaults(s.handler()))
+	return s
+}
+
+// BaseURL returns url which should be passed to accountclient.NewAccountClient
+func (s *Server) BaseURL() string {
+	return s.URL + "/v1"
+}
+
+// Accounts returns copy of all stored accounts in order of their creation
+func (s *Server) Accounts() []models.AccountDataResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts := make([]models.AccountDataResponse, 0, len(s.order))
+	for _, id := range s.order {
+		accounts = append(accounts, *s.accounts[id])
+	}
+	return accounts
+}
+
+// Reset removes all stored accounts
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accounts = make(map[uuid.UUID]*models.AccountDataResponse)
+	s.order = nil
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(healthPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "up"})
+	})
+	mux.HandleFunc(accountsPath, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			s.createAccount(w, r)
+		case http.MethodGet:
+			s.listAccounts(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc(accountsPath+"/", func(w http.ResponseWriter, r *http.Request) {
+		accountID := strings.TrimPrefix(r.URL.Path, accountsPath+"/")
+		switch r.Method {
+		case http.MethodGet:
+			s.fetchAccount(w, accountID)
+		case http.MethodDelete:
+			s.deleteAccount(w, r, accountID)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	return mux
+}
+
+func (s *Server) createAccount(w http.ResponseWriter, r *http.Request) {
+	var request models.CreateAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %s", err.Error()))
+		return
+	}
+	if errMsg := validateCreateRequest(&request); errMsg != "" {
+		writeError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.accounts[request.Data.ID]; ok {
+		writeError(w, http.StatusConflict, "Account cannot be created as it violates a duplicate constraint")
+		return
+	}
+
+	account, err := newAccount(request.Data, s.now().UTC())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.accounts[account.ID] = account
+	s.order = append(s.order, account.ID)
+
+	writeJSON(w, http.StatusCreated, accountResponse{Data: account, Links: responseLinks{Self: accountLink(account.ID)}})
+}
+
+func (s *Server) fetchAccount(w http.ResponseWriter, accountID string) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "id is not a valid uuid")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.accounts[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, accountResponse{Data: account, Links: responseLinks{Self: accountLink(id)}})
+}
+
+func (s *Server) deleteAccount(w http.ResponseWriter, r *http.Request, accountID string) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "id is not a valid uuid")
+		return
+	}
+	version, err := strconv.ParseInt(r.URL.Query().Get("version"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid version number")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.accounts[id]
+	if !ok {
+		// fake api responds to deletion of not existing account with empty body
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if *account.Version != version {
+		writeError(w, http.StatusConflict, "invalid version")
+		return
+	}
+
+	delete(s.accounts, id)
+	for i, orderedID := range s.order {
+		if orderedID == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listAccounts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	pageNumber, pageSize, errMsg := pagination(query.Get("page[number]"), query.Get("page[size]"))
+	if errMsg != "" {
+		writeError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	s.mu.Lock()
+	matching := make([]*models.AccountDataResponse, 0)
+	for _, id := range s.order {
+		if account := s.accounts[id]; matchesFilters(account, query) {
+			matching = append(matching, account)
+		}
+	}
+	s.mu.Unlock()
+
+	lastPage := 0
+	if len(matching) > 0 {
+		lastPage = (len(matching) - 1) / pageSize
+	}
+	start := pageNumber * pageSize
+	page := make([]*models.AccountDataResponse, 0)
+	if start < len(matching) {
+		end := start + pageSize
+		if end > len(matching) {
+			end = len(matching)
+		}
+		page = matching[start:end]
+	}
+
+	links := responseLinks{
+		First: pageLink(query, 0),
+		Last:  pageLink(query, lastPage),
+		Self:  pageLink(query, pageNumber),
+	}
+	if pageNumber < lastPage {
+		links.Next = pageLink(query, pageNumber+1)
+	}
+	if pageNumber > 0 {
+		links.Prev = pageLink(query, pageNumber-1)
+	}
+	writeJSON(w, http.StatusOK, accountsResponse{Data: page, Links: links})
+}
+
+func pagination(number, size string) (pageNumber, pageSize int, errMsg string) {
+	pageSize = defaultPageSize
+	if number != "" {
+		parsed, err := strconv.Atoi(number)
+		if err != nil || parsed < 0 {
+			return 0, 0, "page[number] must be a non negative integer"
+		}
+		pageNumber = parsed
+	}
+	if size != "" {
+		parsed, err := strconv.Atoi(size)
+		if err != nil || parsed < 1 {
+			return 0, 0, "page[size] must be a positive integer"
+		}
+		pageSize = parsed
+	}
+	return pageNumber, pageSize, ""
+}
+
+// matchesFilters checks filter[<field>] query parameters. Multiple values of one filter are separated with comma
+func matchesFilters(account *models.AccountDataResponse, query map[string][]string) bool {
+	attributes := account.Attributes
+	if attributes == nil {
+		attributes = &models.AccountAttributesResponse{}
+	}
+	country := ""
+	if attributes.Country != nil {
+		country = *attributes.Country
+	}
+
+	fields := map[string]string{
+		"organisation_id": account.OrganisationID.String(),
+		"account_number":  attributes.AccountNumber,
+		"bank_id":         attributes.BankID,
+		"bank_id_code":    attributes.BankIDCode,
+		"country":         country,
+		"iban":            attributes.Iban,
+	}
+
+	for key, values := range query {
+		if !strings.HasPrefix(key, "filter[") || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		field := strings.TrimSuffix(strings.TrimPrefix(key, "filter["), "]")
+		value, ok := fields[field]
+		if !ok || len(values) == 0 {
+			return false
+		}
+		if !contains(strings.Split(values[0], ","), value) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func validateCreateRequest(request *models.CreateAccountRequest) string {
+	if request.Data == nil {
+		return validationFailure(1, "data in body is required")
+	}
+
+	dataErrs := make([]string, 0)
+	if request.Data.ID == uuid.Nil {
+		dataErrs = append(dataErrs, "id in body is required")
+	}
+	if request.Data.OrganisationID == uuid.Nil {
+		dataErrs = append(dataErrs, "organisation_id in body is required")
+	}
+	if request.Data.Type == "" {
+		dataErrs = append(dataErrs, "type in body is required")
+	} else if request.Data.Type != accountType {
+		dataErrs = append(dataErrs, fmt.Sprintf("type in body should be one of [%s]", accountType))
+	}
+	if request.Data.Version != nil && *request.Data.Version < 0 {
+		dataErrs = append(dataErrs, "version in body should be greater than or equal to 0")
+	}
+
+	attributes := request.Data.Attributes
+	if attributes == nil {
+		dataErrs = append(dataErrs, "attributes in body is required")
+		return validationFailure(2, dataErrs...)
+	}
+
+	attributeErrs := make([]string, 0)
+	switch {
+	case attributes.Country == nil:
+		attributeErrs = append(attributeErrs, "country in body is required")
+	case !countryPattern.MatchString(*attributes.Country):
+		attributeErrs = append(attributeErrs, fmt.Sprintf("country in body should match '%s'", countryPattern))
+	}
+	switch {
+	case len(attributes.Name) == 0:
+		attributeErrs = append(attributeErrs, "name in body is required")
+	case len(attributes.Name) > 4:
+		attributeErrs = append(attributeErrs, "name in body should have at most 4 items")
+	}
+
+	errs := make([]string, 0)
+	if len(dataErrs) > 0 {
+		errs = append(errs, validationFailure(2, dataErrs...))
+	}
+	if len(attributeErrs) > 0 {
+		errs = append(errs, validationFailure(3, attributeErrs...))
+	}
+	return strings.Join(errs, "\n")
+}
+
+// validationFailure formats errors in the same way as fake api does, each level of nested object adds another
+// "validation failure list:" line
+func validationFailure(depth int, errs ...string) string {
+	if len(errs) == 0 {
+		return ""
+	}
+	return strings.Repeat(validationList+"\n", depth) + strings.Join(errs, "\n")
+}
+
+func newAccount(data *models.CreateAccountData, now time.Time) (*models.AccountDataResponse, error) {
+	// attributes in request and response have the same json representation
+	rawAttributes, err := json.Marshal(data.Attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store account attributes: %w", err)
+	}
+	var attributes models.AccountAttributesResponse
+	if err = json.Unmarshal(rawAttributes, &attributes); err != nil {
+		return nil, fmt.Errorf("failed to store account attributes: %w", err)
+	}
+
+	version := int64(0)
+	return &models.AccountDataResponse{
+		Attributes:     &attributes,
+		ID:             data.ID,
+		OrganisationID: data.OrganisationID,
+		Type:           data.Type,
+		Version:        &version,
+		CreatedOn:      now,
+		ModifiedOn:     now,
+	}, nil
+}
+
+func accountLink(id uuid.UUID) string {
+	return fmt.Sprintf("%s/%s", accountsPath, id)
+}
+
+func pageLink(query map[string][]string, pageNumber int) string {
+	params := make([]string, 0, len(query))
+	for key, values := range query {
+		if key == "page[number]" || len(values) == 0 {
+			continue
+		}
+		params = append(params, fmt.Sprintf("%s=%s", key, values[0]))
+	}
+	params = append(params, fmt.Sprintf("page[number]=%d", pageNumber))
+	sort.Strings(params)
+	return fmt.Sprintf("%s?%s", accountsPath, strings.Join(params, "&"))
+}
+
+func writeError(w http.ResponseWriter, statusCode int, errMsg string) {
+	writeJSON(w, statusCode, errorResponse{ErrorMessage: errMsg})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
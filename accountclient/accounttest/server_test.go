@@ -0,0 +1,147 @@
+package accounttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeServerSuite struct {
+	suite.Suite
+
+	server *Server
+}
+
+func TestFakeServer(t *testing.T) {
+	suite.Run(t, &fakeServerSuite{})
+}
+
+func (s *fakeServerSuite) SetupTest() {
+	s.server = NewServer()
+}
+
+func (s *fakeServerSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *fakeServerSuite) TestCreateAccountValidation() {
+	testCases := map[string]struct {
+		body           string
+		expectedStatus int
+		expectedErrMsg string
+	}{
+		"should require country": {
+			body:           `{"data":{"id":"%s","organisation_id":"%s","type":"accounts","attributes":{"name":["Sam"]}}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrMsg: "validation failure list:\nvalidation failure list:\nvalidation failure list:\ncountry in body is required",
+		},
+		"should require type": {
+			body:           `{"data":{"id":"%s","organisation_id":"%s","attributes":{"name":["Sam"],"country":"GB"}}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrMsg: "validation failure list:\nvalidation failure list:\ntype in body is required",
+		},
+		"should create valid account": {
+			body:           `{"data":{"id":"%s","organisation_id":"%s","type":"accounts","attributes":{"name":["Sam"],"country":"GB"}}}`,
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for name, tc := range testCases {
+		s.Run(name, func() {
+			// when
+			res, err := http.Post(s.server.BaseURL()+"/organisation/accounts", "application/json",
+				strings.NewReader(fmt.Sprintf(tc.body, uuid.New(), uuid.New())))
+
+			// then
+			s.Require().NoError(err)
+			defer res.Body.Close()
+			s.Assert().Equal(tc.expectedStatus, res.StatusCode)
+			if tc.expectedErrMsg != "" {
+				var errRes errorResponse
+				s.Require().NoError(json.NewDecoder(res.Body).Decode(&errRes))
+				s.Assert().Equal(tc.expectedErrMsg, errRes.ErrorMessage)
+			}
+		})
+	}
+}
+
+func (s *fakeServerSuite) TestDeleteAccount() {
+	// given
+	accountID := s.createAccount(uuid.New())
+
+	s.Run("should reject deletion with invalid version", func() {
+		s.Assert().Equal(http.StatusConflict, s.deleteAccount(accountID, "1"))
+	})
+
+	s.Run("should delete account with valid version", func() {
+		s.Assert().Equal(http.StatusNoContent, s.deleteAccount(accountID, "0"))
+		s.Assert().Empty(s.server.Accounts())
+	})
+
+	s.Run("should respond with not found when account doesn't exist", func() {
+		s.Assert().Equal(http.StatusNotFound, s.deleteAccount(accountID, "0"))
+	})
+}
+
+func (s *fakeServerSuite) TestListAccounts() {
+	// given
+	organisationID := uuid.New()
+	created := []uuid.UUID{s.createAccount(organisationID), s.createAccount(organisationID), s.createAccount(organisationID)}
+	s.createAccount(uuid.New())
+
+	// when
+	res, err := http.Get(fmt.Sprintf("%s/organisation/accounts?filter[organisation_id]=%s&page[number]=1&page[size]=2",
+		s.server.BaseURL(), organisationID))
+
+	// then
+	s.Require().NoError(err)
+	defer res.Body.Close()
+	s.Require().Equal(http.StatusOK, res.StatusCode)
+	var page accountsResponse
+	s.Require().NoError(json.NewDecoder(res.Body).Decode(&page))
+	s.Require().Len(page.Data, 1)
+	s.Assert().Equal(created[2], page.Data[0].ID)
+	s.Assert().Empty(page.Links.Next)
+	s.Assert().NotEmpty(page.Links.Prev)
+}
+
+func (s *fakeServerSuite) createAccount(organisationID uuid.UUID) uuid.UUID {
+	accountID := uuid.New()
+	body := fmt.Sprintf(`{"data":{"id":"%s","organisation_id":"%s","type":"accounts","attributes":{"name":["Sam"],"country":"GB"}}}`,
+		accountID, organisationID)
+	res, err := http.Post(s.server.BaseURL()+"/organisation/accounts", "application/json", strings.NewReader(body))
+	s.Require().NoError(err)
+	defer res.Body.Close()
+	s.Require().Equal(http.StatusCreated, res.StatusCode)
+	return accountID
+}
+
+func (s *fakeServerSuite) deleteAccount(accountID uuid.UUID, version string) int {
+	req, err := http.NewRequest(http.MethodDelete,
+		fmt.Sprintf("%s/organisation/accounts/%s?version=%s", s.server.BaseURL(), accountID, version), http.NoBody)
+	s.Require().NoError(err)
+	res, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer res.Body.Close()
+	return res.StatusCode
+}
+
+func (s *fakeServerSuite) TestHealth() {
+	s.Run("should report that server is up", func() {
+		// when
+		res, err := http.Get(s.server.BaseURL() + "/health")
+
+		// then
+		s.Require().NoError(err)
+		defer res.Body.Close()
+		s.Assert().Equal(http.StatusOK, res.StatusCode)
+		var body map[string]string
+		s.Require().NoError(json.NewDecoder(res.Body).Decode(&body))
+		s.Assert().Equal("up", body["status"])
+	})
+}
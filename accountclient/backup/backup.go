@@ -0,0 +1,281 @@
+// Package backup allows to export all accounts of an organisation to an archive and restore them later,
+// i.e. in another environment
+//
+// Archive is gzipped tar with two files: manifest.json describing the export and accounts.jsonl with one account
+// in each line. Manifest contains number of accounts and sha256 checksum of accounts.jsonl, which are verified
+// before any account is restored. Restored accounts keep their ids, organisation id can be remapped:
+//
+//	manifest, err := backup.Export(ctx, client, organisationID, file)
+//	...
+//	manifest, summary, err := backup.Restore(ctx, otherClient, file, backup.RestoreOptions{OrganisationID: newOrganisationID})
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient"
+	"github.com/arturskrzydlo/account-api-client/accountclient/importer"
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+const (
+	// FormatVersion is version of archive format written by Export
+	FormatVersion = 1
+
+	manifestFile = "manifest.json"
+	accountsFile = "accounts.jsonl"
+	pageSize     = 100
+)
+
+// ErrInvalidArchive is returned by Restore when archive is damaged or has unsupported format
+var ErrInvalidArchive = errors.New("invalid backup archive")
+
+// Manifest describes content of archive
+type Manifest struct {
+	FormatVersion  int       `json:"format_version"`
+	OrganisationID uuid.UUID `json:"organisation_id"`
+	ExportedAt     time.Time `json:"exported_at"`
+	AccountCount   int       `json:"account_count"`
+	// AccountsSHA256 is hex encoded sha256 checksum of accounts.jsonl
+	AccountsSHA256 string `json:"accounts_sha256"`
+}
+
+// Export writes all accounts of organisation listed with client to w as archive and returns its Manifest
+func Export(ctx context.Context, client accountclient.AccountAPI, organisationID uuid.UUID, w io.Writer) (*Manifest, error) {
+	var accounts bytes.Buffer
+	count := 0
+	listOptions := accountclient.ListOptions{
+		PageSize: pageSize,
+		Filters:  map[string][]string{accountclient.FilterOrganisationID: {organisationID.String()}},
+	}
+	for {
+		page, err := client.ListAccounts(ctx, listOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list accounts to export: %w", err)
+		}
+		for _, account := range page.Data {
+			line, err := json.Marshal(account)
+			if err != nil {
+				return nil, fmt.Errorf("failed to serialize exported account: %w", err)
+			}
+			accounts.Write(append(line, '\n'))
+			count++
+		}
+		if page.Links == nil || page.Links.Next == "" || len(page.Data) == 0 {
+			break
+		}
+		listOptions.PageNumber++
+	}
+
+	checksum := sha256.Sum256(accounts.Bytes())
+	manifest := &Manifest{
+		FormatVersion:  FormatVersion,
+		OrganisationID: organisationID,
+		ExportedAt:     time.Now().UTC(),
+		AccountCount:   count,
+		AccountsSHA256: hex.EncodeToString(checksum[:]),
+	}
+	manifestContent, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize manifest: %w", err)
+	}
+
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	if err = writeFile(tarWriter, manifestFile, manifestContent, manifest.ExportedAt); err != nil {
+		return nil, err
+	}
+	if err = writeFile(tarWriter, accountsFile, accounts.Bytes(), manifest.ExportedAt); err != nil {
+		return nil, err
+	}
+	if err = tarWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err = gzipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return manifest, nil
+}
+
+func writeFile(tarWriter *tar.Writer, name string, content []byte, modTime time.Time) error {
+	header := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(content)), ModTime: modTime}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	if _, err := tarWriter.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	return nil
+}
+
+// RestoreOptions modify restore of archive
+type RestoreOptions struct {
+	// OrganisationID of restored accounts. When it's not set, organisation id from archive is used
+	OrganisationID uuid.UUID
+	// ImportOptions are used to create restored accounts, i.e. to set concurrency, checkpoint or report
+	ImportOptions []importer.Option
+}
+
+// Restore verifies archive read from r and creates all its accounts with client. Accounts are created with importer,
+// so restore run again skips accounts which already exist. Accounts which already exist are fetched and compared
+// with archive, the ones with different data are counted as failed with error matching importer.ErrAccountMismatch.
+// Fields owned by api, like version or status, aren't restored. Importer validation isn't applied, as accounts have
+// been already accepted by api. As conflicts are counted as errors by circuit breaker, restore of archive which
+// has been already partially restored may need accountclient.WithoutCircuitBreaker passed with
+// importer.WithCallOptions. Manifest of archive is returned together with summary of created accounts.
+// ErrInvalidArchive is returned when archive doesn't match its manifest
+func Restore(ctx context.Context, client accountclient.AccountAPI, r io.Reader, options RestoreOptions) (*Manifest, importer.Summary, error) {
+	manifest, accounts, err := readArchive(r)
+	if err != nil {
+		return nil, importer.Summary{}, err
+	}
+
+	organisationID := options.OrganisationID
+	if organisationID == uuid.Nil {
+		organisationID = manifest.OrganisationID
+	}
+	source := &accountsSource{accounts: accounts, organisationID: organisationID}
+	importOptions := append(append([]importer.Option{}, options.ImportOptions...),
+		importer.WithValidation(validateRestored), importer.WithVerifyExisting())
+	summary, err := importer.New(client, importOptions...).Import(ctx, source)
+	if err != nil {
+		return manifest, summary, fmt.Errorf("failed to restore accounts: %w", err)
+	}
+	return manifest, summary, nil
+}
+
+// validateRestored checks only id, which is required to skip accounts which have been already restored
+func validateRestored(request *models.CreateAccountRequest) error {
+	if request.Data.ID == uuid.Nil {
+		return errors.New("account has no id")
+	}
+	return nil
+}
+
+// ReadManifest reads and verifies archive from r without restoring it
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	manifest, _, err := readArchive(r)
+	return manifest, err
+}
+
+// readArchive reads archive and verifies that accounts match manifest
+func readArchive(r io.Reader) (*Manifest, []*models.AccountDataResponse, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+	defer gzipReader.Close()
+
+	files := make(map[string][]byte)
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, nextErr := tarReader.Next()
+		if errors.Is(nextErr, io.EOF) {
+			break
+		}
+		if nextErr != nil {
+			return nil, nil, fmt.Errorf("%w: %s", ErrInvalidArchive, nextErr)
+		}
+		if header.Name != manifestFile && header.Name != accountsFile {
+			continue
+		}
+		if files[header.Name], err = io.ReadAll(tarReader); err != nil {
+			return nil, nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+		}
+	}
+
+	manifestContent, ok := files[manifestFile]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s is missing", ErrInvalidArchive, manifestFile)
+	}
+	var manifest Manifest
+	if err = json.Unmarshal(manifestContent, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid manifest: %s", ErrInvalidArchive, err)
+	}
+	if manifest.FormatVersion != FormatVersion {
+		return nil, nil, fmt.Errorf("%w: unsupported format version %d", ErrInvalidArchive, manifest.FormatVersion)
+	}
+
+	accountsContent, ok := files[accountsFile]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s is missing", ErrInvalidArchive, accountsFile)
+	}
+	checksum := sha256.Sum256(accountsContent)
+	if hex.EncodeToString(checksum[:]) != manifest.AccountsSHA256 {
+		return nil, nil, fmt.Errorf("%w: checksum of %s doesn't match manifest", ErrInvalidArchive, accountsFile)
+	}
+
+	accounts := make([]*models.AccountDataResponse, 0, manifest.AccountCount)
+	decoder := json.NewDecoder(bytes.NewReader(accountsContent))
+	for decoder.More() {
+		var account models.AccountDataResponse
+		if err = decoder.Decode(&account); err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid account: %s", ErrInvalidArchive, err)
+		}
+		accounts = append(accounts, &account)
+	}
+	if len(accounts) != manifest.AccountCount {
+		return nil, nil, fmt.Errorf("%w: archive has %d accounts, manifest %d",
+			ErrInvalidArchive, len(accounts), manifest.AccountCount)
+	}
+	return &manifest, accounts, nil
+}
+
+// accountsSource is importer.Source of accounts from archive
+type accountsSource struct {
+	accounts       []*models.AccountDataResponse
+	organisationID uuid.UUID
+	next           int
+}
+
+func (s *accountsSource) Next() (importer.Row, error) {
+	if s.next >= len(s.accounts) {
+		return importer.Row{}, io.EOF
+	}
+	account := s.accounts[s.next]
+	s.next++
+
+	return importer.Row{
+		Line:    s.next,
+		Request: &models.CreateAccountRequest{Data: createAccountData(account, s.organisationID)},
+	}, nil
+}
+
+// createAccountData maps exported account to data of restored one. Fields set by api, i.e. version, creation time
+// or status, are left out
+func createAccountData(account *models.AccountDataResponse, organisationID uuid.UUID) *models.CreateAccountData {
+	data := &models.CreateAccountData{ID: account.ID, OrganisationID: organisationID, Type: importer.AccountType}
+	attributes := account.Attributes
+	if attributes == nil {
+		return data
+	}
+	data.Attributes = &models.CreateAccountAttributes{
+		AccountClassification:   attributes.AccountClassification,
+		AccountMatchingOptOut:   attributes.AccountMatchingOptOut,
+		AccountNumber:           attributes.AccountNumber,
+		AlternativeNames:        attributes.AlternativeNames,
+		BankID:                  attributes.BankID,
+		BankIDCode:              attributes.BankIDCode,
+		BaseCurrency:            attributes.BaseCurrency,
+		Bic:                     attributes.Bic,
+		Country:                 attributes.Country,
+		Iban:                    attributes.Iban,
+		JointAccount:            attributes.JointAccount,
+		Name:                    attributes.Name,
+		SecondaryIdentification: attributes.SecondaryIdentification,
+		Switched:                attributes.Switched,
+	}
+	return data
+}
@@ -0,0 +1,195 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient"
+	"github.com/arturskrzydlo/account-api-client/accountclient/accounttest"
+	"github.com/arturskrzydlo/account-api-client/accountclient/importer"
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+type backupSuite struct {
+	suite.Suite
+}
+
+func TestBackup(t *testing.T) {
+	suite.Run(t, &backupSuite{})
+}
+
+func (s *backupSuite) TearDownTest() {
+	hystrix.Flush()
+}
+
+func (s *backupSuite) newClient(server *accounttest.Server) *accountclient.Client {
+	client, err := accountclient.NewAccountClient(server.BaseURL())
+	s.Require().NoError(err)
+	return client
+}
+
+func (s *backupSuite) createAccounts(client *accountclient.Client, organisationID uuid.UUID, count int) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, count)
+	for i := 0; i < count; i++ {
+		country := "GB"
+		request := &models.CreateAccountRequest{Data: &models.CreateAccountData{
+			Attributes: &models.CreateAccountAttributes{
+				Country: &country, Name: []string{"Samantha Holder"}, BankID: "400300", BankIDCode: "GBDSC",
+			},
+			ID:             uuid.New(),
+			OrganisationID: organisationID,
+			Type:           "accounts",
+		}}
+		_, err := client.CreateAccount(context.Background(), request)
+		s.Require().NoError(err)
+		ids = append(ids, request.Data.ID)
+	}
+	return ids
+}
+
+func (s *backupSuite) TestExportAndRestore() {
+	// given
+	source := accounttest.NewServer()
+	defer source.Close()
+	sourceClient := s.newClient(source)
+	organisationID := uuid.New()
+	// more accounts than fit on one page
+	exportedIDs := s.createAccounts(sourceClient, organisationID, pageSize+5)
+	s.createAccounts(sourceClient, uuid.New(), 2)
+
+	var archive bytes.Buffer
+	manifest, err := Export(context.Background(), sourceClient, organisationID, &archive)
+	s.Require().NoError(err)
+	country := "GB"
+
+	s.Run("should export all accounts of organisation", func() {
+		// when
+		readManifest, err := ReadManifest(bytes.NewReader(archive.Bytes()))
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(pageSize+5, manifest.AccountCount)
+		s.Assert().Equal(FormatVersion, readManifest.FormatVersion)
+		s.Assert().Equal(organisationID, readManifest.OrganisationID)
+		s.Assert().Equal(manifest.AccountsSHA256, readManifest.AccountsSHA256)
+	})
+
+	s.Run("should restore accounts with the same ids in new organisation", func() {
+		// given
+		target := accounttest.NewServer()
+		defer target.Close()
+		targetClient := s.newClient(target)
+		targetOrganisationID := uuid.New()
+
+		// when
+		_, summary, err := Restore(context.Background(), targetClient, bytes.NewReader(archive.Bytes()),
+			RestoreOptions{OrganisationID: targetOrganisationID, ImportOptions: []importer.Option{importer.WithConcurrency(2)}})
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(pageSize+5, summary.Created)
+		restored := target.Accounts()
+		s.Require().Len(restored, len(exportedIDs))
+		restoredIDs := make([]uuid.UUID, 0, len(restored))
+		for _, account := range restored {
+			s.Assert().Equal(targetOrganisationID, account.OrganisationID)
+			s.Assert().Equal("400300", account.Attributes.BankID)
+			restoredIDs = append(restoredIDs, account.ID)
+		}
+		s.Assert().ElementsMatch(exportedIDs, restoredIDs)
+
+		// when restored again
+		_, summary, err = Restore(context.Background(), targetClient, bytes.NewReader(archive.Bytes()), RestoreOptions{
+			OrganisationID: targetOrganisationID,
+			ImportOptions:  []importer.Option{importer.WithCallOptions(accountclient.WithoutCircuitBreaker())},
+		})
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(pageSize+5, summary.Exists)
+		s.Assert().Len(target.Accounts(), len(exportedIDs))
+	})
+
+	s.Run("should report accounts which exist with different data as failed", func() {
+		// given
+		target := accounttest.NewServer()
+		defer target.Close()
+		targetClient := s.newClient(target)
+		_, err := targetClient.CreateAccount(context.Background(), &models.CreateAccountRequest{
+			Data: &models.CreateAccountData{
+				Attributes: &models.CreateAccountAttributes{Country: &country, Name: []string{"Other Holder"}, BankID: "400300"},
+				ID:         exportedIDs[0], OrganisationID: organisationID, Type: "accounts",
+			},
+		})
+		s.Require().NoError(err)
+		var report bytes.Buffer
+
+		// when
+		_, summary, err := Restore(context.Background(), targetClient, bytes.NewReader(archive.Bytes()), RestoreOptions{
+			ImportOptions: []importer.Option{
+				importer.WithCallOptions(accountclient.WithoutCircuitBreaker()), importer.WithReport(&report),
+			},
+		})
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(pageSize+4, summary.Created)
+		s.Assert().Equal(0, summary.Exists)
+		s.Assert().Equal(1, summary.Failed)
+		s.Assert().Contains(report.String(), exportedIDs[0].String()+`,failed,"`+importer.ErrAccountMismatch.Error()+
+			`: bank_id_code, name"`)
+	})
+
+	s.Run("should not restore archive which doesn't match manifest", func() {
+		// given
+		tampered := s.tamperAccounts(archive.Bytes())
+		target := accounttest.NewServer()
+		defer target.Close()
+
+		// when
+		_, _, err := Restore(context.Background(), s.newClient(target), bytes.NewReader(tampered), RestoreOptions{})
+
+		// then
+		s.Assert().ErrorIs(err, ErrInvalidArchive)
+		s.Assert().Empty(target.Accounts())
+	})
+}
+
+// tamperAccounts rewrites archive with the last account removed from accounts.jsonl
+func (s *backupSuite) tamperAccounts(archive []byte) []byte {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+	s.Require().NoError(err)
+	tarReader := tar.NewReader(gzipReader)
+
+	var tampered bytes.Buffer
+	gzipWriter := gzip.NewWriter(&tampered)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		s.Require().NoError(err)
+		content, err := io.ReadAll(tarReader)
+		s.Require().NoError(err)
+		if header.Name == accountsFile {
+			lines := bytes.SplitAfter(bytes.TrimSpace(content), []byte("\n"))
+			content = bytes.Join(lines[:len(lines)-1], nil)
+		}
+		header.Size = int64(len(content))
+		s.Require().NoError(tarWriter.WriteHeader(header))
+		_, err = tarWriter.Write(content)
+		s.Require().NoError(err)
+	}
+	s.Require().NoError(tarWriter.Close())
+	s.Require().NoError(gzipWriter.Close())
+	return tampered.Bytes()
+}
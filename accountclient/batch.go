@@ -0,0 +1,282 @@
+package accountclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+// defaultBatchConcurrency matches default limit of concurrent requests of circuit breaker
+const defaultBatchConcurrency = 10
+
+// Errors matched with errors.Is by errors of batch operations. Such errors also wrap RequestError returned by api
+var (
+	// ErrAccountNotFound is error of account which doesn't exist
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrAccountExists is error of account which can't be created because account with the same id exists
+	ErrAccountExists = errors.New("account already exists")
+	// ErrVersionConflict is error of account which can't be deleted because its version has changed
+	ErrVersionConflict = errors.New("account version conflict")
+)
+
+// BatchOptions modify batch operations
+type BatchOptions struct {
+	// Concurrency is maximum number of requests sent at the same time, 10 by default.
+	// Requests above concurrency limit of circuit breaker are rejected, so with higher values
+	// it has to be raised too, see WithMaxConcurrency
+	Concurrency int
+	// RateLimit is maximum number of items started per second. 0 means no limit.
+	// It's applied on top of client's WithRateLimit, which limits every request attempt including retries,
+	// so batch is limited by the lower of both limits and its items are counted against client's limit
+	RateLimit float64
+	// StopOnError stops batch at the first failed item. By default, batch continues and stops only when
+	// circuit breaker opens or ctx is done
+	StopOnError bool
+}
+
+func (o BatchOptions) concurrency() int {
+	if o.Concurrency < 1 {
+		return defaultBatchConcurrency
+	}
+	return o.Concurrency
+}
+
+// BatchResult describes result of each item of batch operation. Account ids are in order of items
+type BatchResult struct {
+	Succeeded []uuid.UUID
+	Failed    []BatchFailure
+	// Skipped items haven't been started because batch has been stopped
+	Skipped []uuid.UUID
+}
+
+// BatchFailure is failed item of batch operation. Err matches ErrAccountNotFound, ErrAccountExists
+// or ErrVersionConflict when api rejected item for such reason
+type BatchFailure struct {
+	AccountID uuid.UUID
+	Err       error
+}
+
+// AccountVersion identifies account deleted by DeleteAccounts. Nil Version means that current version is fetched
+// before account is deleted
+type AccountVersion struct {
+	AccountID uuid.UUID
+	Version   *int64
+}
+
+// classifiedError marks error with one of batch errors, i.e. ErrAccountNotFound
+type classifiedError struct {
+	err   error
+	class error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() error {
+	return e.err
+}
+
+func (e *classifiedError) Is(target error) bool {
+	return target == e.class
+}
+
+// classify marks err with class when it's RequestError with statusCode
+func classify(err error, statusCode int, class error) error {
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) && reqErr.StatusCode == statusCode {
+		return &classifiedError{err: err, class: class}
+	}
+	return err
+}
+
+// runBatch calls do for indexes from 0 to n-1 with worker pool configured by batchOptions. Items are started in order
+// of indexes, so items from dispatched index on haven't been started. stopErr is error which stopped the batch
+func runBatch(ctx context.Context, n int, batchOptions BatchOptions, do func(ctx context.Context, index int) error,
+) (errs []error, dispatched int, stopErr error) {
+	errs = make([]error, n)
+	if n == 0 {
+		return errs, 0, nil
+	}
+
+	// in-flight items get ctx, so stopped batch doesn't cancel them
+	dispatchCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	var limiter *tokenBucket
+	if batchOptions.RateLimit > 0 {
+		limiter = newTokenBucket(batchOptions.RateLimit, 1)
+	}
+
+	var mu sync.Mutex
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < batchOptions.concurrency() && i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				err := do(ctx, index)
+				if err == nil {
+					continue
+				}
+				mu.Lock()
+				errs[index] = err
+				if stopErr == nil && (batchOptions.StopOnError || errors.Is(err, hystrix.ErrCircuitOpen)) {
+					stopErr = err
+					stop()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for dispatched < n {
+		if dispatchCtx.Err() != nil {
+			break
+		}
+		if limiter != nil && limiter.wait(dispatchCtx) != nil {
+			break
+		}
+		select {
+		case indexes <- dispatched:
+			dispatched++
+		case <-dispatchCtx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if stopErr == nil && ctx.Err() != nil {
+		stopErr = ctx.Err()
+	}
+	return errs, dispatched, stopErr
+}
+
+// FetchAccounts fetches accounts with ids using worker pool configured by batchOptions.
+// Accounts are returned in order of ids, with nil for accounts which couldn't be fetched. Errors of such accounts are
+// returned in map by account id, errors of accounts which don't exist match ErrAccountNotFound.
+// When circuit breaker opens or ctx is done, accounts which haven't been fetched yet aren't fetched anymore.
+// They get the same error, which is also returned as the last value
+// CallOption are applied to every fetch
+func (c *Client) FetchAccounts(ctx context.Context, ids []uuid.UUID, batchOptions BatchOptions, options ...CallOption,
+) ([]*models.AccountResponse, map[uuid.UUID]error, error) {
+	ctx, end, err := c.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer end()
+
+	accounts := make([]*models.AccountResponse, len(ids))
+	errs, dispatched, stopErr := runBatch(ctx, len(ids), batchOptions, func(ctx context.Context, index int) error {
+		account, err := c.FetchAccount(ctx, ids[index], options...)
+		accounts[index] = account
+		return err
+	})
+
+	errsByID := make(map[uuid.UUID]error)
+	for index, err := range errs[:dispatched] {
+		if err != nil {
+			errsByID[ids[index]] = classify(err, http.StatusNotFound, ErrAccountNotFound)
+		}
+	}
+	if stopErr == nil {
+		return accounts, errsByID, nil
+	}
+	stopErr = fmt.Errorf("failed to fetch accounts: %w", stopErr)
+	for _, accountID := range ids[dispatched:] {
+		errsByID[accountID] = stopErr
+	}
+	return accounts, errsByID, stopErr
+}
+
+// CreateAccounts creates accounts using worker pool configured by batchOptions. Errors of accounts which already
+// exist match ErrAccountExists. Batch stops when circuit breaker opens, ctx is done or, with
+// BatchOptions.StopOnError, at the first failure. Error which stopped the batch is returned together with BatchResult
+// CallOption are applied to every create
+func (c *Client) CreateAccounts(ctx context.Context, requests []*models.CreateAccountRequest, batchOptions BatchOptions,
+	options ...CallOption,
+) (*BatchResult, error) {
+	ctx, end, err := c.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
+	ids := make([]uuid.UUID, len(requests))
+	for i, request := range requests {
+		if request != nil && request.Data != nil {
+			ids[i] = request.Data.ID
+		}
+	}
+	errs, dispatched, stopErr := runBatch(ctx, len(requests), batchOptions, func(ctx context.Context, index int) error {
+		if ids[index] == uuid.Nil {
+			return errors.New("request has no account id")
+		}
+		_, err := c.CreateAccount(ctx, requests[index], options...)
+		return classify(err, http.StatusConflict, ErrAccountExists)
+	})
+	return batchResult(ids, errs, dispatched), wrapStopErr("failed to create accounts", stopErr)
+}
+
+// DeleteAccounts deletes accounts using worker pool configured by batchOptions. Errors of accounts which don't exist
+// match ErrAccountNotFound and errors of accounts with outdated version match ErrVersionConflict.
+// Batch stops like CreateAccounts. CallOption are applied to every request
+func (c *Client) DeleteAccounts(ctx context.Context, accounts []AccountVersion, batchOptions BatchOptions,
+	options ...CallOption,
+) (*BatchResult, error) {
+	ctx, end, err := c.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
+	ids := make([]uuid.UUID, len(accounts))
+	for i, account := range accounts {
+		ids[i] = account.AccountID
+	}
+	// cached account may have outdated version
+	fetchOptions := append(append([]CallOption{}, options...), WithoutCache())
+	errs, dispatched, stopErr := runBatch(ctx, len(accounts), batchOptions, func(ctx context.Context, index int) error {
+		version := accounts[index].Version
+		if version == nil {
+			account, err := c.FetchAccount(ctx, ids[index], fetchOptions...)
+			if err != nil {
+				return classify(err, http.StatusNotFound, ErrAccountNotFound)
+			}
+			version = account.Data.Version
+		}
+		err := c.DeleteAccount(ctx, ids[index], version, options...)
+		err = classify(err, http.StatusNotFound, ErrAccountNotFound)
+		return classify(err, http.StatusConflict, ErrVersionConflict)
+	})
+	return batchResult(ids, errs, dispatched), wrapStopErr("failed to delete accounts", stopErr)
+}
+
+func batchResult(ids []uuid.UUID, errs []error, dispatched int) *BatchResult {
+	result := &BatchResult{Succeeded: make([]uuid.UUID, 0), Failed: make([]BatchFailure, 0), Skipped: make([]uuid.UUID, 0)}
+	for index, err := range errs[:dispatched] {
+		if err != nil {
+			result.Failed = append(result.Failed, BatchFailure{AccountID: ids[index], Err: err})
+			continue
+		}
+		result.Succeeded = append(result.Succeeded, ids[index])
+	}
+	result.Skipped = append(result.Skipped, ids[dispatched:]...)
+	return result
+}
+
+func wrapStopErr(message string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
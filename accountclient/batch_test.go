@@ -0,0 +1,247 @@
+package accountclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/accounttest"
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+func (s *accountAPIClientSuite) TestFetchAccounts() {
+	defer hystrix.Flush()
+
+	s.Run("should return accounts in order of ids and errors of missing accounts", func() {
+		// given
+		fakeAPI := accounttest.NewServer()
+		defer fakeAPI.Close()
+		accountsClient, err := NewAccountClient(fakeAPI.BaseURL())
+		s.Require().NoError(err)
+		ids := make([]uuid.UUID, 0)
+		for i := 0; i < 5; i++ {
+			account := createAccountRequest()
+			_, err = accountsClient.CreateAccount(context.Background(), account)
+			s.Require().NoError(err)
+			ids = append(ids, account.Data.ID)
+		}
+		missingID := uuid.New()
+		ids = append(ids[:2], append([]uuid.UUID{missingID}, ids[2:]...)...)
+
+		// when
+		accounts, errs, err := accountsClient.FetchAccounts(context.Background(), ids, BatchOptions{Concurrency: 3},
+			WithoutCircuitBreaker())
+
+		// then
+		s.Require().NoError(err)
+		s.Require().Len(accounts, len(ids))
+		for i, accountID := range ids {
+			if accountID == missingID {
+				s.Assert().Nil(accounts[i])
+				continue
+			}
+			s.Assert().Equal(accountID, accounts[i].Data.ID)
+		}
+		s.Require().Len(errs, 1)
+		s.Assert().ErrorIs(errs[missingID], ErrAccountNotFound)
+		var reqErr *RequestError
+		s.Assert().ErrorAs(errs[missingID], &reqErr)
+	})
+
+	s.Run("should not send more requests than concurrency", func() {
+		// given
+		var inFlight, maxInFlight int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				seen := atomic.LoadInt32(&maxInFlight)
+				if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			_, _ = w.Write([]byte(`{"data":{"type":"accounts"}}`))
+		}))
+		defer server.Close()
+		accountsClient, err := NewAccountClient(server.URL)
+		s.Require().NoError(err)
+		ids := make([]uuid.UUID, 20)
+		for i := range ids {
+			ids[i] = uuid.New()
+		}
+
+		// when
+		_, errs, err := accountsClient.FetchAccounts(context.Background(), ids, BatchOptions{Concurrency: 4})
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Empty(errs)
+		s.Assert().LessOrEqual(atomic.LoadInt32(&maxInFlight), int32(4))
+	})
+
+	s.Run("should stop fetching when circuit breaker opens", func() {
+		// given
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		accountsClient, err := NewAccountClient(server.URL)
+		s.Require().NoError(err)
+		ids := make([]uuid.UUID, 100)
+		for i := range ids {
+			ids[i] = uuid.New()
+		}
+
+		// when
+		accounts, errs, err := accountsClient.FetchAccounts(context.Background(), ids, BatchOptions{Concurrency: 1})
+
+		// then
+		s.Assert().ErrorIs(err, hystrix.ErrCircuitOpen)
+		s.Assert().Len(accounts, len(ids))
+		s.Assert().Len(errs, len(ids))
+		s.Assert().ErrorIs(errs[ids[len(ids)-1]], hystrix.ErrCircuitOpen)
+		s.Assert().Less(int(atomic.LoadInt32(&requests)), len(ids))
+		for _, fetchErr := range errs {
+			s.Assert().False(errors.Is(fetchErr, ErrAccountNotFound))
+		}
+	})
+}
+
+func (s *accountAPIClientSuite) TestCreateAndDeleteAccounts() {
+	fakeAPI := accounttest.NewServer()
+	defer fakeAPI.Close()
+	accountsClient, err := NewAccountClient(fakeAPI.BaseURL())
+	s.Require().NoError(err)
+
+	s.Run("should create accounts and report existing ones as failed", func() {
+		// given
+		fakeAPI.Reset()
+		existing := createAccountRequest()
+		_, err := accountsClient.CreateAccount(context.Background(), existing)
+		s.Require().NoError(err)
+		requests := []*models.CreateAccountRequest{createAccountRequest(), existing, createAccountRequest()}
+
+		// when
+		result, err := accountsClient.CreateAccounts(context.Background(), requests, BatchOptions{Concurrency: 2},
+			WithoutCircuitBreaker())
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal([]uuid.UUID{requests[0].Data.ID, requests[2].Data.ID}, result.Succeeded)
+		s.Require().Len(result.Failed, 1)
+		s.Assert().Equal(existing.Data.ID, result.Failed[0].AccountID)
+		s.Assert().ErrorIs(result.Failed[0].Err, ErrAccountExists)
+		s.Assert().Empty(result.Skipped)
+		s.Assert().Len(fakeAPI.Accounts(), 3)
+	})
+
+	s.Run("should skip remaining accounts after the first failure when stopping on error", func() {
+		// given
+		fakeAPI.Reset()
+		existing := createAccountRequest()
+		_, err := accountsClient.CreateAccount(context.Background(), existing)
+		s.Require().NoError(err)
+		requests := []*models.CreateAccountRequest{existing, createAccountRequest(), createAccountRequest()}
+
+		// when
+		result, err := accountsClient.CreateAccounts(context.Background(), requests,
+			BatchOptions{Concurrency: 1, StopOnError: true}, WithoutCircuitBreaker())
+
+		// then
+		s.Assert().ErrorIs(err, ErrAccountExists)
+		s.Assert().Empty(result.Succeeded)
+		s.Assert().Len(result.Failed, 1)
+		s.Assert().Equal([]uuid.UUID{requests[1].Data.ID, requests[2].Data.ID}, result.Skipped)
+		s.Assert().Len(fakeAPI.Accounts(), 1)
+	})
+
+	s.Run("should delete accounts with given or current version", func() {
+		// given
+		fakeAPI.Reset()
+		requests := []*models.CreateAccountRequest{createAccountRequest(), createAccountRequest()}
+		_, err := accountsClient.CreateAccounts(context.Background(), requests, BatchOptions{})
+		s.Require().NoError(err)
+		wrongVersion := int64(3)
+		missingID := uuid.New()
+		accounts := []AccountVersion{
+			{AccountID: requests[0].Data.ID},
+			{AccountID: requests[1].Data.ID, Version: &wrongVersion},
+			{AccountID: missingID},
+		}
+
+		// when
+		result, err := accountsClient.DeleteAccounts(context.Background(), accounts, BatchOptions{Concurrency: 3},
+			WithoutCircuitBreaker())
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal([]uuid.UUID{requests[0].Data.ID}, result.Succeeded)
+		s.Require().Len(result.Failed, 2)
+		s.Assert().ErrorIs(result.Failed[0].Err, ErrVersionConflict)
+		s.Assert().Equal(missingID, result.Failed[1].AccountID)
+		s.Assert().ErrorIs(result.Failed[1].Err, ErrAccountNotFound)
+		s.Assert().Len(fakeAPI.Accounts(), 1)
+	})
+
+	s.Run("should not start more items per second than rate limit", func() {
+		// given
+		fakeAPI.Reset()
+		requests := make([]*models.CreateAccountRequest, 5)
+		for i := range requests {
+			requests[i] = createAccountRequest()
+		}
+		start := time.Now()
+
+		// when
+		result, err := accountsClient.CreateAccounts(context.Background(), requests, BatchOptions{RateLimit: 50})
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Len(result.Succeeded, 5)
+		// the first item is started immediately, next ones every 20ms
+		s.Assert().GreaterOrEqual(time.Since(start), 80*time.Millisecond)
+	})
+}
+
+func (s *accountAPIClientSuite) TestTokenBucket() {
+	s.Run("should allow burst and then rate events per second", func() {
+		// given
+		now := time.Now()
+		bucket := newTokenBucket(10, 2)
+		bucket.now = func() time.Time { return now }
+
+		// when
+		first, second, third := bucket.reserve(), bucket.reserve(), bucket.reserve()
+		now = now.Add(time.Second)
+		afterRefill := bucket.reserve()
+
+		// then
+		s.Assert().Zero(first)
+		s.Assert().Zero(second)
+		s.Assert().Equal(100*time.Millisecond, third)
+		s.Assert().Zero(afterRefill)
+	})
+
+	s.Run("should stop waiting when context is done", func() {
+		// given
+		bucket := newTokenBucket(0.001, 1)
+		s.Require().NoError(bucket.wait(context.Background()))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// when
+		err := bucket.wait(ctx)
+
+		// then
+		s.Assert().ErrorIs(err, context.DeadlineExceeded)
+	})
+}
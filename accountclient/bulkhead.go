@@ -0,0 +1,91 @@
+package accountclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrTooManyRequests is returned when request hasn't got a free slot of Client concurrency limit in time,
+// see WithMaxConcurrency
+var ErrTooManyRequests = errors.New("too many concurrent requests")
+
+// MaxConcurrency limits number of requests sent by Client at the same time, see WithMaxConcurrency
+type MaxConcurrency struct {
+	// Reads is limit of concurrent GET requests
+	Reads int
+	// Writes is limit of concurrent requests modifying accounts
+	Writes int
+	// MaxWait is how long request waits for a free slot before it fails with ErrTooManyRequests.
+	// 0 means that request fails immediately when all slots are taken
+	MaxWait time.Duration
+}
+
+// WithMaxConcurrency limits number of requests sent at the same time to n reads and n writes. Reads and writes have
+// separate limits, so i.e. bulk delete can't starve fetches. Request waits for a free slot up to maxWait
+// and fails with ErrTooManyRequests when there is none. Slot is held until request and its retries have finished.
+// Circuit breaker allows as many concurrent requests as both limits together. Circuit breaker is shared by clients
+// of the process with the same limits, clients with different limits or without them have separate ones
+func WithMaxConcurrency(n int, maxWait time.Duration) ClientOption {
+	return WithReadWriteConcurrency(n, n, maxWait)
+}
+
+// WithReadWriteConcurrency is WithMaxConcurrency with different limits of reads and writes
+func WithReadWriteConcurrency(reads, writes int, maxWait time.Duration) ClientOption {
+	return func(cfg *ClientConfig) {
+		if reads < 1 || writes < 1 {
+			cfg.setErr(errors.New("max concurrency must be greater than 0"))
+			return
+		}
+		cfg.MaxConcurrency = &MaxConcurrency{Reads: reads, Writes: writes, MaxWait: maxWait}
+	}
+}
+
+// bulkhead holds separate pools of slots for reads and writes
+type bulkhead struct {
+	reads   chan struct{}
+	writes  chan struct{}
+	maxWait time.Duration
+}
+
+func newBulkhead(maxConcurrency *MaxConcurrency) *bulkhead {
+	if maxConcurrency == nil {
+		return nil
+	}
+	return &bulkhead{
+		reads:   make(chan struct{}, maxConcurrency.Reads),
+		writes:  make(chan struct{}, maxConcurrency.Writes),
+		maxWait: maxConcurrency.MaxWait,
+	}
+}
+
+// acquire takes slot for request with given method and returns function releasing it
+func (b *bulkhead) acquire(ctx context.Context, method string) (release func(), err error) {
+	pool, name := b.writes, "writes"
+	if method == http.MethodGet || method == http.MethodHead {
+		pool, name = b.reads, "reads"
+	}
+	release = func() { <-pool }
+
+	select {
+	case pool <- struct{}{}:
+		return release, nil
+	default:
+	}
+	if b.maxWait <= 0 {
+		return nil, fmt.Errorf("%w: all %d slots for %s are taken", ErrTooManyRequests, cap(pool), name)
+	}
+
+	timer := time.NewTimer(b.maxWait)
+	defer timer.Stop()
+	select {
+	case pool <- struct{}{}:
+		return release, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("%w: no slot for %s has been freed within %s", ErrTooManyRequests, name, b.maxWait)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,167 @@
+package accountclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/google/uuid"
+)
+
+func (s *accountAPIClientSuite) TestMaxConcurrency() {
+	defer hystrix.Flush()
+
+	// startBlockedFetch starts fetch which blocks until server is released and waits until server receives it
+	startBlockedFetch := func(client *Client, requests *int32) <-chan error {
+		done := make(chan error, 1)
+		go func() {
+			_, err := client.FetchAccount(context.Background(), uuid.New())
+			done <- err
+		}()
+		s.Require().Eventually(func() bool { return atomic.LoadInt32(requests) == 1 }, time.Second, time.Millisecond)
+		return done
+	}
+
+	s.Run("should reject read when all read slots are taken but allow write", func() {
+		// given
+		release := make(chan struct{})
+		server, requests, _ := blockingServer(release)
+		defer server.Close()
+		accountsClient, err := NewAccountClient(server.URL, WithMaxConcurrency(1, 0))
+		s.Require().NoError(err)
+		blocked := startBlockedFetch(accountsClient, requests)
+		version := int64(0)
+
+		// when
+		_, fetchErr := accountsClient.FetchAccount(context.Background(), uuid.New())
+		deleteErr := make(chan error, 1)
+		go func() {
+			deleteErr <- accountsClient.DeleteAccount(context.Background(), uuid.New(), &version)
+		}()
+		s.Require().Eventually(func() bool { return atomic.LoadInt32(requests) == 2 }, time.Second, time.Millisecond)
+		close(release)
+
+		// then
+		s.Assert().ErrorIs(fetchErr, ErrTooManyRequests)
+		s.Assert().NoError(<-deleteErr)
+		s.Assert().NoError(<-blocked)
+	})
+
+	s.Run("should wait for a free slot up to max wait", func() {
+		// given
+		release := make(chan struct{})
+		server, requests, _ := blockingServer(release)
+		defer server.Close()
+		accountsClient, err := NewAccountClient(server.URL, WithMaxConcurrency(1, time.Second))
+		s.Require().NoError(err)
+		blocked := startBlockedFetch(accountsClient, requests)
+		time.AfterFunc(20*time.Millisecond, func() { close(release) })
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New())
+
+		// then
+		s.Assert().NoError(err)
+		s.Assert().NoError(<-blocked)
+	})
+
+	s.Run("should fail when no slot has been freed within max wait", func() {
+		// given
+		release := make(chan struct{})
+		server, requests, _ := blockingServer(release)
+		defer server.Close()
+		accountsClient, err := NewAccountClient(server.URL, WithMaxConcurrency(1, 20*time.Millisecond))
+		s.Require().NoError(err)
+		blocked := startBlockedFetch(accountsClient, requests)
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New())
+
+		// then
+		s.Assert().ErrorIs(err, ErrTooManyRequests)
+		close(release)
+		s.Assert().NoError(<-blocked)
+	})
+
+	s.Run("should allow circuit breaker to send all requests which got a slot", func() {
+		// when
+		client, err := NewAccountClient("http://some-api.com", WithReadWriteConcurrency(15, 5, 0))
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(20, hystrix.GetCircuitSettings()[client.commandName].MaxConcurrentRequests)
+	})
+
+	s.Run("should send all requests which got a slot when circuit of client without limit already exists", func() {
+		// given
+		released := make(chan struct{})
+		close(released)
+		fastServer, _, _ := blockingServer(released)
+		defer fastServer.Close()
+		plainClient, err := NewAccountClient(fastServer.URL)
+		s.Require().NoError(err)
+		_, err = plainClient.FetchAccount(context.Background(), uuid.New())
+		s.Require().NoError(err)
+		release := make(chan struct{})
+		server, requests, _ := blockingServer(release)
+		defer server.Close()
+		client, err := NewAccountClient(server.URL, WithMaxConcurrency(20, time.Second))
+		s.Require().NoError(err)
+
+		// when
+		errs := make(chan error, 20)
+		for i := 0; i < 20; i++ {
+			go func() {
+				_, err := client.FetchAccount(context.Background(), uuid.New())
+				errs <- err
+			}()
+		}
+		s.Require().Eventually(func() bool { return atomic.LoadInt32(requests) == 20 }, time.Second, time.Millisecond)
+		close(release)
+
+		// then
+		for i := 0; i < 20; i++ {
+			s.Assert().NoError(<-errs)
+		}
+	})
+
+	s.Run("should hold slot until request has finished when circuit breaker times out", func() {
+		// given
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}))
+		defer server.Close()
+		var sending int32
+		transport := RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&sending, 1)
+			defer atomic.AddInt32(&sending, -1)
+			return http.DefaultTransport.RoundTrip(request)
+		})
+		client, err := NewAccountClient(server.URL, WithMaxConcurrency(1, 0),
+			WithCustomHTTPClient(&http.Client{Transport: transport}))
+		s.Require().NoError(err)
+		hystrix.ConfigureCommand(client.commandName, hystrix.CommandConfig{Timeout: 20, MaxConcurrentRequests: 2})
+
+		// when
+		_, err = client.FetchAccount(context.Background(), uuid.New())
+
+		// then
+		s.Assert().ErrorIs(err, hystrix.ErrTimeout)
+		s.Assert().Equal(int32(0), atomic.LoadInt32(&sending))
+		s.Assert().Empty(client.bulkhead.reads)
+	})
+
+	s.Run("should not create client with invalid max concurrency", func() {
+		// when
+		_, err := NewAccountClient("http://some-api.com", WithMaxConcurrency(0, time.Second))
+
+		// then
+		s.Assert().Error(err)
+	})
+}
@@ -0,0 +1,195 @@
+package accountclient
+
+import (
+	"container/list"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+// Cache stores accounts fetched by Client, see WithCache. Implementations must be safe for concurrent use
+type Cache interface {
+	// Get returns account cached for accountID, second value is false when account isn't cached or has expired
+	Get(accountID uuid.UUID) (*models.AccountResponse, bool)
+	// Set caches account for accountID
+	Set(accountID uuid.UUID, account *models.AccountResponse)
+	// Delete removes account cached for accountID
+	Delete(accountID uuid.UUID)
+}
+
+// WithCache makes FetchAccount read accounts through cache. Accounts are cached under their id and removed from cache
+// when they are deleted or created with the same Client, also when such call fails, i.e. with conflict caused by
+// outdated version. Changes made by other clients are visible after cached account expires, so cache should be used
+// only where slightly outdated accounts are acceptable. Single call can skip reading cache with WithoutCache
+func WithCache(cache Cache) ClientOption {
+	return func(cfg *ClientConfig) {
+		cfg.Cache = cache
+	}
+}
+
+// WithoutCache makes a single FetchAccount call fetch account from api even if it's cached.
+// Fetched account still replaces cached one
+func WithoutCache() CallOption {
+	return func(cfg *callConfig) {
+		cfg.skipCache = true
+	}
+}
+
+// fetchCached returns copy of cached account, so callers can't modify cached value
+func (c *Client) fetchCached(accountID uuid.UUID) (*models.AccountResponse, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+	account, ok := c.cache.Get(accountID)
+	if !ok {
+		return nil, false
+	}
+	account, err := copyAccount(account)
+	return account, err == nil
+}
+
+// storeCached caches fetched account unless any account has been invalidated since generation was read,
+// so fetch which raced with delete doesn't cache deleted account
+func (c *Client) storeCached(accountID uuid.UUID, account *models.AccountResponse, generation int64) {
+	if c.cache == nil || c.cacheGeneration.Load() != generation {
+		return
+	}
+	if cached, err := copyAccount(account); err == nil {
+		c.cache.Set(accountID, cached)
+	}
+}
+
+func (c *Client) invalidateCached(accountID uuid.UUID) {
+	if c.cache != nil {
+		c.cacheGeneration.Add(1)
+		c.cache.Delete(accountID)
+	}
+}
+
+func copyAccount(account *models.AccountResponse) (*models.AccountResponse, error) {
+	content, err := json.Marshal(account)
+	if err != nil {
+		return nil, err
+	}
+	var accountCopy models.AccountResponse
+	if err = json.Unmarshal(content, &accountCopy); err != nil {
+		return nil, err
+	}
+	return &accountCopy, nil
+}
+
+// CacheStats are counters of LRUCache
+type CacheStats struct {
+	// Hits is number of Get calls which found account
+	Hits int64
+	// Misses is number of Get calls which haven't found account, including expired ones
+	Misses int64
+	// Evictions is number of accounts removed because cache was full
+	Evictions int64
+	// Size is number of currently cached accounts
+	Size int
+}
+
+// LRUCache is in-memory Cache keeping up to maxSize accounts. When it's full, least recently used account is removed
+type LRUCache struct {
+	maxSize int
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]*list.Element
+	// order has the most recently used entry at the front
+	order *list.List
+	stats CacheStats
+}
+
+type lruEntry struct {
+	accountID uuid.UUID
+	account   *models.AccountResponse
+	expiresAt time.Time
+}
+
+// NewLRUCache creates LRUCache for maxSize accounts, which expire after ttl. ttl equal to 0 means that accounts
+// don't expire. maxSize lower than 1 is treated as 1
+func NewLRUCache(maxSize int, ttl time.Duration) *LRUCache {
+	if maxSize < 1 {
+		maxSize = 1
+	}
+	return &LRUCache{
+		maxSize: maxSize,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[uuid.UUID]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Get returns cached account and marks it as recently used
+func (l *LRUCache) Get(accountID uuid.UUID) (*models.AccountResponse, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.entries[accountID]
+	if !ok {
+		l.stats.Misses++
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if l.ttl > 0 && !l.now().Before(entry.expiresAt) {
+		l.remove(element)
+		l.stats.Misses++
+		return nil, false
+	}
+	l.order.MoveToFront(element)
+	l.stats.Hits++
+	return entry.account, true
+}
+
+// Set caches account, removing the least recently used account when cache is full
+func (l *LRUCache) Set(accountID uuid.UUID, account *models.AccountResponse) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expiresAt := l.now().Add(l.ttl)
+	if element, ok := l.entries[accountID]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.account = account
+		entry.expiresAt = expiresAt
+		l.order.MoveToFront(element)
+		return
+	}
+	l.entries[accountID] = l.order.PushFront(&lruEntry{accountID: accountID, account: account, expiresAt: expiresAt})
+	if l.order.Len() > l.maxSize {
+		l.remove(l.order.Back())
+		l.stats.Evictions++
+	}
+}
+
+// Delete removes cached account
+func (l *LRUCache) Delete(accountID uuid.UUID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.entries[accountID]; ok {
+		l.remove(element)
+	}
+}
+
+// Stats returns current counters of cache
+func (l *LRUCache) Stats() CacheStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := l.stats
+	stats.Size = l.order.Len()
+	return stats
+}
+
+func (l *LRUCache) remove(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.entries, element.Value.(*lruEntry).accountID)
+}
@@ -0,0 +1,122 @@
+package accountclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/accounttest"
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+func (s *accountAPIClientSuite) TestLRUCache() {
+	newAccount := func(accountID uuid.UUID) *models.AccountResponse {
+		return &models.AccountResponse{Data: &models.AccountDataResponse{ID: accountID}}
+	}
+
+	s.Run("should evict least recently used account when cache is full", func() {
+		// given
+		cache := NewLRUCache(2, 0)
+		first, second, third := uuid.New(), uuid.New(), uuid.New()
+		cache.Set(first, newAccount(first))
+		cache.Set(second, newAccount(second))
+		_, found := cache.Get(first)
+		s.Require().True(found)
+
+		// when
+		cache.Set(third, newAccount(third))
+
+		// then
+		_, firstFound := cache.Get(first)
+		_, secondFound := cache.Get(second)
+		_, thirdFound := cache.Get(third)
+		s.Assert().True(firstFound)
+		s.Assert().False(secondFound)
+		s.Assert().True(thirdFound)
+		s.Assert().Equal(CacheStats{Hits: 3, Misses: 1, Evictions: 1, Size: 2}, cache.Stats())
+	})
+
+	s.Run("should expire account after ttl", func() {
+		// given
+		now := time.Now()
+		cache := NewLRUCache(10, time.Minute)
+		cache.now = func() time.Time { return now }
+		accountID := uuid.New()
+		cache.Set(accountID, newAccount(accountID))
+
+		// when
+		_, foundBeforeTTL := cache.Get(accountID)
+		now = now.Add(time.Minute)
+		_, foundAfterTTL := cache.Get(accountID)
+
+		// then
+		s.Assert().True(foundBeforeTTL)
+		s.Assert().False(foundAfterTTL)
+		s.Assert().Equal(CacheStats{Hits: 1, Misses: 1, Size: 0}, cache.Stats())
+	})
+}
+
+func (s *accountAPIClientSuite) TestFetchAccountWithCache() {
+	fakeAPI := accounttest.NewServer()
+	defer fakeAPI.Close()
+	cache := NewLRUCache(10, time.Minute)
+	accountsClient, err := NewAccountClient(fakeAPI.BaseURL(), WithCache(cache))
+	s.Require().NoError(err)
+
+	s.Run("should fetch account from api only once", func() {
+		// given
+		account := createAccountRequest()
+		_, err := accountsClient.CreateAccount(context.Background(), account)
+		s.Require().NoError(err)
+		requestsBefore := fakeAPI.RequestCount()
+
+		// when
+		first, err := accountsClient.FetchAccount(context.Background(), account.Data.ID)
+		s.Require().NoError(err)
+		first.Data.Attributes.BankID = "modified"
+		second, err := accountsClient.FetchAccount(context.Background(), account.Data.ID)
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(1, fakeAPI.RequestCount()-requestsBefore)
+		s.Assert().Equal(account.Data.Attributes.BankID, second.Data.Attributes.BankID)
+	})
+
+	s.Run("should fetch account from api when cache is skipped", func() {
+		// given
+		account := createAccountRequest()
+		_, err := accountsClient.CreateAccount(context.Background(), account)
+		s.Require().NoError(err)
+		_, err = accountsClient.FetchAccount(context.Background(), account.Data.ID)
+		s.Require().NoError(err)
+		requestsBefore := fakeAPI.RequestCount()
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), account.Data.ID, WithoutCache())
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(1, fakeAPI.RequestCount()-requestsBefore)
+	})
+
+	s.Run("should not return account deleted with the same client", func() {
+		// given
+		account := createAccountRequest()
+		created, err := accountsClient.CreateAccount(context.Background(), account)
+		s.Require().NoError(err)
+		_, err = accountsClient.FetchAccount(context.Background(), account.Data.ID)
+		s.Require().NoError(err)
+
+		// when
+		err = accountsClient.DeleteAccount(context.Background(), account.Data.ID, created.Data.Version)
+		s.Require().NoError(err)
+		_, err = accountsClient.FetchAccount(context.Background(), account.Data.ID, WithoutCircuitBreaker())
+
+		// then
+		var reqErr *RequestError
+		s.Require().ErrorAs(err, &reqErr)
+		s.Assert().Equal(http.StatusNotFound, reqErr.StatusCode)
+	})
+}
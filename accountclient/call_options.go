@@ -0,0 +1,72 @@
+package accountclient
+
+import (
+	"net/http"
+	"time"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// CallOption is function which can modify configuration of a single Client method call.
+// Values set by CallOption take precedence over values from ClientConfig only for the call they are passed to
+type CallOption func(cfg *callConfig)
+
+type callConfig struct {
+	timeout            time.Duration
+	retryPolicy        RetryPolicy
+	headers            http.Header
+	skipCircuitBreaker bool
+	skipCache          bool
+}
+
+func newCallConfig(options []CallOption) callConfig {
+	cfg := callConfig{headers: make(http.Header)}
+	for _, option := range options {
+		option(&cfg)
+	}
+	return cfg
+}
+
+// WithCallTimeout overrides http.Client timeout for a single call. The whole call, including retries and delays
+// between them, has to finish within given timeout. It can be longer than client timeout also for calls protected
+// by circuit breaker, i.e. for bulk imports. Calls which time out are counted as circuit breaker errors
+func WithCallTimeout(timeout time.Duration) CallOption {
+	return func(cfg *callConfig) {
+		cfg.timeout = timeout
+	}
+}
+
+// WithCallRetryPolicy overrides RetryPolicy configured on NewAccountClient for a single call
+func WithCallRetryPolicy(retryPolicy RetryPolicy) CallOption {
+	return func(cfg *callConfig) {
+		cfg.retryPolicy = retryPolicy
+	}
+}
+
+// WithCallRetriesOnDefaultRetryPolicy overrides RetryPolicy for a single call with DefaultRetryPolicy
+func WithCallRetriesOnDefaultRetryPolicy(maxRetries int) CallOption {
+	return func(cfg *callConfig) {
+		cfg.retryPolicy = DefaultRetryPolicy{maxRetries: maxRetries}
+	}
+}
+
+// WithHeader sets header on request sent by a single call. It overrides header with the same name set by the client
+func WithHeader(key, value string) CallOption {
+	return func(cfg *callConfig) {
+		cfg.headers.Set(key, value)
+	}
+}
+
+// WithIdempotencyKey sets Idempotency-Key header on request sent by a single call.
+// The same key is sent on every retry of this call
+func WithIdempotencyKey(key string) CallOption {
+	return WithHeader(idempotencyKeyHeader, key)
+}
+
+// WithoutCircuitBreaker sends a single call without circuit breaker. Such call is neither rejected when circuit is open
+// nor counted into circuit breaker error statistics
+func WithoutCircuitBreaker() CallOption {
+	return func(cfg *callConfig) {
+		cfg.skipCircuitBreaker = true
+	}
+}
@@ -0,0 +1,131 @@
+package accountclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/google/uuid"
+)
+
+func (s *accountAPIClientSuite) TestCallOptions() {
+	s.Run("call timeout should override client timeout only for a single call", func() {
+		// given
+		testServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(time.Millisecond * 100)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"data":{}}`))
+		}))
+		defer testServ.Close()
+
+		accountsClient, err := NewAccountClient(testServ.URL)
+		s.Require().NoError(err)
+
+		// when
+		_, errWithTimeout := accountsClient.FetchAccount(context.Background(), uuid.New(),
+			WithCallTimeout(time.Millisecond*10), WithoutCircuitBreaker())
+		_, errWithoutTimeout := accountsClient.FetchAccount(context.Background(), uuid.New())
+
+		// then
+		s.Assert().ErrorIs(errWithTimeout, context.DeadlineExceeded)
+		s.Assert().NoError(errWithoutTimeout)
+		s.Assert().Equal(defaultTimeout, accountsClient.httpClient.Timeout)
+	})
+
+	s.Run("call timeout longer than client timeout should apply to call protected by circuit breaker", func() {
+		// given
+		defer hystrix.Flush()
+		testServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(time.Millisecond * 50)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"data":{}}`))
+		}))
+		defer testServ.Close()
+
+		accountsClient, err := NewAccountClient(testServ.URL,
+			WithCustomHTTPClient(&http.Client{Timeout: time.Millisecond * 10}))
+		s.Require().NoError(err)
+
+		// when
+		_, errWithTimeout := accountsClient.FetchAccount(context.Background(), uuid.New(), WithCallTimeout(time.Second))
+		_, errWithoutTimeout := accountsClient.FetchAccount(context.Background(), uuid.New())
+
+		// then
+		s.Assert().NoError(errWithTimeout)
+		s.Assert().ErrorIs(errWithoutTimeout, context.DeadlineExceeded)
+	})
+
+	s.Run("call timeout should stop waiting for the next retry", func() {
+		// given
+		testServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer testServ.Close()
+
+		accountsClient, err := NewAccountClient(testServ.URL, WithRetriesOnDefaultRetryPolicy(3),
+			WithLinearBackoffStrategy(time.Second))
+		s.Require().NoError(err)
+		start := time.Now()
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New(),
+			WithCallTimeout(time.Millisecond*20), WithoutCircuitBreaker())
+
+		// then
+		s.Assert().ErrorIs(err, context.DeadlineExceeded)
+		s.Assert().Less(time.Since(start), time.Second)
+	})
+
+	s.Run("call retry policy should override client retry policy only for a single call", func() {
+		// given
+		numCalls := 0
+		testServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			numCalls++
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer testServ.Close()
+
+		accountsClient, err := NewAccountClient(testServ.URL)
+		s.Require().NoError(err)
+		maxRetries := 2
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New(),
+			WithCallRetriesOnDefaultRetryPolicy(maxRetries), WithoutCircuitBreaker())
+		s.Require().Error(err)
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New(), WithoutCircuitBreaker())
+		s.Require().Error(err)
+
+		// then
+		s.Assert().Equal(maxRetries+1+1, numCalls)
+	})
+
+	s.Run("call headers should be sent with request and on each retry", func() {
+		// given
+		idempotencyKeys := make([]string, 0)
+		customHeaders := make([]string, 0)
+		testServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idempotencyKeys = append(idempotencyKeys, r.Header.Get("Idempotency-Key"))
+			customHeaders = append(customHeaders, r.Header.Get("X-Custom"))
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer testServ.Close()
+
+		accountsClient, err := NewAccountClient(testServ.URL, WithRetriesOnDefaultRetryPolicy(1))
+		s.Require().NoError(err)
+
+		// when
+		accountVersion := int64(0)
+		err = accountsClient.DeleteAccount(context.Background(), uuid.New(), &accountVersion,
+			WithIdempotencyKey("some-key"), WithHeader("X-Custom", "custom"), WithoutCircuitBreaker())
+
+		// then
+		var reqErr *RequestError
+		s.Assert().True(errors.As(err, &reqErr))
+		s.Assert().Equal([]string{"some-key", "some-key"}, idempotencyKeys)
+		s.Assert().Equal([]string{"custom", "custom"}, customHeaders)
+	})
+}
@@ -0,0 +1,278 @@
+// Package cassette allows to record exchanges with account api and replay them in tests
+//
+// Recorder in ModeRecord sends requests to api and stores them together with responses in a cassette file
+// (json format). In ModeReplay the same requests are served from cassette file without any network traffic,
+// so tests which have been recorded against real or docker api can be run in CI without it.
+//
+// Volatile values are scrubbed before they are stored: credentials and signatures in headers, dates,
+// timestamps and ids. Ids are replaced with placeholders numbered in the order they appear,
+// so a replayed test may use new random ids as long as it makes the same calls in the same order.
+//
+// Recorder can be plugged into accountclient.Client with accountclient.WithMiddleware(recorder.Middleware())
+// or with accountclient.WithCustomHTTPClient(&http.Client{Transport: recorder})
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient"
+)
+
+// Mode of Recorder
+type Mode int
+
+const (
+	// ModeRecord sends requests to api and records them
+	ModeRecord Mode = iota
+	// ModeReplay serves requests from cassette file
+	ModeReplay
+)
+
+const cassetteVersion = 1
+
+// ErrInteractionNotFound is returned in ModeReplay when cassette has no unused interaction matching request
+var ErrInteractionNotFound = errors.New("cassette: no recorded interaction matches request")
+
+// headers scrubbed by default as they contain credentials, signatures or values unique for each request
+var defaultScrubbedHeaders = []string{"Authorization", "Signature", "Digest", "Date", "X-Request-Id", "Set-Cookie"}
+
+// Cassette is content of cassette file
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request with its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is recorded request. URL contains only path and query, as host differs between environments
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Response is recorded response
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Option modifies Recorder
+type Option func(r *Recorder)
+
+// WithScrubbedHeaders adds headers which values are scrubbed in recorded requests and responses
+func WithScrubbedHeaders(headers ...string) Option {
+	return func(r *Recorder) {
+		for _, header := range headers {
+			r.scrubbedHeaders[http.CanonicalHeaderKey(header)] = true
+		}
+	}
+}
+
+// WithTransport sets transport used in ModeRecord when Recorder is used as http.RoundTripper.
+// By default, http.DefaultTransport is used
+func WithTransport(transport http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.transport = transport
+	}
+}
+
+// Recorder records or replays exchanges with account api. It is safe for concurrent use, but calls
+// made concurrently may be recorded in different order than they are replayed
+type Recorder struct {
+	path            string
+	mode            Mode
+	transport       http.RoundTripper
+	scrubbedHeaders map[string]bool
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+	ids      *idMapper
+}
+
+// New creates Recorder which stores cassette in file under path. In ModeReplay the file is loaded and has to exist.
+// In ModeRecord the file is overwritten with every recorded interaction
+func New(path string, mode Mode, options ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:            path,
+		mode:            mode,
+		transport:       http.DefaultTransport,
+		scrubbedHeaders: make(map[string]bool),
+		cassette:        &Cassette{Version: cassetteVersion, Interactions: make([]Interaction, 0)},
+		ids:             newIDMapper(),
+	}
+	for _, header := range defaultScrubbedHeaders {
+		r.scrubbedHeaders[http.CanonicalHeaderKey(header)] = true
+	}
+	for _, option := range options {
+		option(r)
+	}
+
+	if mode == ModeReplay {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		if err = json.Unmarshal(content, r.cassette); err != nil {
+			return nil, fmt.Errorf("failed to unmarshall cassette: %w", err)
+		}
+		if r.cassette.Version != cassetteVersion {
+			return nil, fmt.Errorf("unsupported cassette version %d", r.cassette.Version)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+// RoundTrip records or replays request depending on Recorder mode
+func (r *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	return r.roundTrip(request, r.transport)
+}
+
+// Middleware returns Recorder as accountclient.Middleware. In ModeRecord requests are sent with wrapped transport
+func (r *Recorder) Middleware() accountclient.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return accountclient.RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			return r.roundTrip(request, next)
+		})
+	}
+}
+
+// Cassette returns copy of recorded or loaded interactions
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Cassette{
+		Version:      r.cassette.Version,
+		Interactions: append([]Interaction(nil), r.cassette.Interactions...),
+	}
+}
+
+func (r *Recorder) roundTrip(request *http.Request, transport http.RoundTripper) (*http.Response, error) {
+	body, err := accountclient.ReadBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(request, body)
+	}
+	return r.record(request, body, transport)
+}
+
+func (r *Recorder) record(request *http.Request, body []byte, transport http.RoundTripper) (*http.Response, error) {
+	// body of request without GetBody has been read, so clone with the same body is sent instead
+	sentRequest := request
+	if body != nil {
+		sentRequest = request.Clone(request.Context())
+		sentRequest.Body = io.NopCloser(bytes.NewReader(body))
+		sentRequest.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	response, err := transport.RoundTrip(sentRequest)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body to record: %w", err)
+	}
+	response.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: Request{
+			Method:  request.Method,
+			URL:     r.ids.scrub(request.URL.RequestURI()),
+			Headers: r.scrubHeaders(request.Header),
+			Body:    scrubTimestamps(r.ids.scrub(string(body))),
+		},
+		Response: Response{
+			StatusCode: response.StatusCode,
+			Headers:    r.scrubHeaders(response.Header),
+			Body:       scrubTimestamps(r.ids.scrub(string(resBody))),
+		},
+	})
+	if err = r.save(); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (r *Recorder) replay(request *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	url := r.ids.scrub(request.URL.RequestURI())
+	scrubbedBody := scrubTimestamps(r.ids.scrub(string(body)))
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || interaction.Request.Method != request.Method || interaction.Request.URL != url ||
+			interaction.Request.Body != scrubbedBody {
+			continue
+		}
+		r.used[i] = true
+
+		resBody := r.ids.unscrub(interaction.Response.Body)
+		headers := interaction.Response.Headers.Clone()
+		if headers == nil {
+			headers = make(http.Header)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        headers,
+			Body:          io.NopCloser(strings.NewReader(resBody)),
+			ContentLength: int64(len(resBody)),
+			Request:       request,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrInteractionNotFound, request.Method, url)
+}
+
+func (r *Recorder) scrubHeaders(headers http.Header) http.Header {
+	scrubbed := make(http.Header, len(headers))
+	for name, values := range headers {
+		switch {
+		case name == "Content-Length":
+			continue
+		case r.scrubbedHeaders[name]:
+			scrubbed[name] = []string{scrubbedValue}
+		default:
+			scrubbed[name] = append([]string(nil), values...)
+		}
+	}
+	return scrubbed
+}
+
+func (r *Recorder) save() error {
+	content, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshall cassette: %w", err)
+	}
+	if err = os.WriteFile(r.path, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package cassette
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient"
+	"github.com/arturskrzydlo/account-api-client/accountclient/accounttest"
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+type cassetteSuite struct {
+	suite.Suite
+}
+
+func TestCassette(t *testing.T) {
+	suite.Run(t, &cassetteSuite{})
+}
+
+// accountFlow creates, fetches and deletes account with new random ids and returns them
+func (s *cassetteSuite) accountFlow(client *accountclient.Client) (accountID, organisationID uuid.UUID) {
+	country := "GB"
+	request := &models.CreateAccountRequest{Data: &models.CreateAccountData{
+		Attributes:     &models.CreateAccountAttributes{Name: []string{"Samantha Holder"}, Country: &country},
+		ID:             uuid.New(),
+		OrganisationID: uuid.New(),
+		Type:           "accounts",
+	}}
+
+	created, err := client.CreateAccount(context.Background(), request)
+	s.Require().NoError(err)
+	s.Require().Equal(request.Data.ID, created.Data.ID)
+
+	fetched, err := client.FetchAccount(context.Background(), request.Data.ID)
+	s.Require().NoError(err)
+	s.Require().Equal(request.Data.ID, fetched.Data.ID)
+	s.Require().Equal(request.Data.OrganisationID, fetched.Data.OrganisationID)
+	s.Require().False(fetched.Data.CreatedOn.IsZero())
+
+	s.Require().NoError(client.DeleteAccount(context.Background(), request.Data.ID, fetched.Data.Version))
+
+	_, err = client.FetchAccount(context.Background(), request.Data.ID)
+	var reqErr *accountclient.RequestError
+	s.Require().ErrorAs(err, &reqErr)
+	s.Require().Equal(http.StatusNotFound, reqErr.StatusCode)
+
+	return request.Data.ID, request.Data.OrganisationID
+}
+
+func (s *cassetteSuite) TestRecordAndReplay() {
+	// given
+	cassettePath := filepath.Join(s.T().TempDir(), "account_flow.json")
+	fakeAPI := accounttest.NewServer()
+	defer fakeAPI.Close()
+
+	recorder, err := New(cassettePath, ModeRecord)
+	s.Require().NoError(err)
+	recordingClient, err := accountclient.NewAccountClient(fakeAPI.BaseURL(),
+		accountclient.WithMiddleware(recorder.Middleware()))
+	s.Require().NoError(err)
+	accountID, organisationID := s.accountFlow(recordingClient)
+
+	s.Run("should store scrubbed interactions", func() {
+		// when
+		content, err := os.ReadFile(cassettePath)
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Len(recorder.Cassette().Interactions, 4)
+		s.Assert().NotContains(string(content), accountID.String())
+		s.Assert().NotContains(string(content), organisationID.String())
+		s.Assert().Contains(string(content), scrubbedTimestamp)
+		for _, interaction := range recorder.Cassette().Interactions {
+			s.Assert().Equal([]string{scrubbedValue}, interaction.Request.Headers["X-Request-Id"])
+		}
+	})
+
+	s.Run("should replay interactions with new ids without api", func() {
+		// given
+		replayer, err := New(cassettePath, ModeReplay)
+		s.Require().NoError(err)
+		replayingClient, err := accountclient.NewAccountClient("http://127.0.0.1:1/v1",
+			accountclient.WithCustomHTTPClient(&http.Client{Transport: replayer}))
+		s.Require().NoError(err)
+
+		// when
+		replayedAccountID, _ := s.accountFlow(replayingClient)
+
+		// then
+		s.Assert().NotEqual(accountID, replayedAccountID)
+	})
+
+	s.Run("should fail request which hasn't been recorded", func() {
+		// given
+		replayer, err := New(cassettePath, ModeReplay)
+		s.Require().NoError(err)
+		replayingClient, err := accountclient.NewAccountClient("http://127.0.0.1:1/v1",
+			accountclient.WithMiddleware(replayer.Middleware()))
+		s.Require().NoError(err)
+		accountVersion := int64(5)
+
+		// when
+		err = replayingClient.DeleteAccount(context.Background(), uuid.New(), &accountVersion,
+			accountclient.WithoutCircuitBreaker())
+
+		// then
+		s.Assert().ErrorIs(err, ErrInteractionNotFound)
+	})
+
+	s.Run("should not modify body of recorded request", func() {
+		// given
+		recorder, err := New(filepath.Join(s.T().TempDir(), "create.json"), ModeRecord)
+		s.Require().NoError(err)
+		body := []byte(`{"data":{"id":"` + uuid.NewString() + `","type":"accounts"}}`)
+		request, err := http.NewRequest(http.MethodPost, fakeAPI.BaseURL()+"/organisation/accounts",
+			bytes.NewReader(body))
+		s.Require().NoError(err)
+		originalBody := request.Body
+
+		// when
+		response, err := recorder.Middleware()(http.DefaultTransport).RoundTrip(request)
+
+		// then
+		s.Require().NoError(err)
+		_ = response.Body.Close()
+		s.Assert().Equal(originalBody, request.Body)
+		remainingBody, err := io.ReadAll(request.Body)
+		s.Require().NoError(err)
+		s.Assert().Equal(body, remainingBody)
+		s.Require().Len(recorder.Cassette().Interactions, 1)
+		s.Assert().NotEmpty(recorder.Cassette().Interactions[0].Request.Body)
+	})
+}
@@ -0,0 +1,80 @@
+package cassette
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	scrubbedValue     = "[scrubbed]"
+	scrubbedTimestamp = "1970-01-01T00:00:00Z"
+	placeholderFormat = "00000000-0000-4000-8000-%012d"
+	placeholderPrefix = "00000000-0000-4000-8000-"
+)
+
+var (
+	uuidPattern      = regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`)
+)
+
+// idMapper replaces volatile ids with placeholders numbered in order in which ids are seen.
+// The same test replayed with new random ids sees them in the same order, so placeholders
+// from cassette can be mapped back to ids of the current run
+type idMapper struct {
+	toPlaceholder   map[string]string
+	fromPlaceholder map[string]string
+	next            int
+}
+
+func newIDMapper() *idMapper {
+	return &idMapper{
+		toPlaceholder:   make(map[string]string),
+		fromPlaceholder: make(map[string]string),
+		next:            1,
+	}
+}
+
+// scrub replaces ids in s with placeholders, assigning new placeholders to ids seen for the first time
+func (m *idMapper) scrub(s string) string {
+	return uuidPattern.ReplaceAllStringFunc(s, func(id string) string {
+		id = strings.ToLower(id)
+		if placeholder, ok := m.toPlaceholder[id]; ok {
+			return placeholder
+		}
+		placeholder := fmt.Sprintf(placeholderFormat, m.next)
+		m.next++
+		m.toPlaceholder[id] = placeholder
+		m.fromPlaceholder[placeholder] = id
+		return placeholder
+	})
+}
+
+// unscrub replaces placeholders in s with ids of the current run. Placeholders which don't have id yet, were generated
+// by api during recording, so they are used as ids as they are
+func (m *idMapper) unscrub(s string) string {
+	return uuidPattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+		if id, ok := m.fromPlaceholder[placeholder]; ok {
+			return id
+		}
+		if number, ok := placeholderNumber(placeholder); ok && number >= m.next {
+			m.next = number + 1
+		}
+		m.toPlaceholder[placeholder] = placeholder
+		m.fromPlaceholder[placeholder] = placeholder
+		return placeholder
+	})
+}
+
+func placeholderNumber(placeholder string) (int, bool) {
+	if !strings.HasPrefix(placeholder, placeholderPrefix) {
+		return 0, false
+	}
+	number, err := strconv.Atoi(strings.TrimPrefix(placeholder, placeholderPrefix))
+	return number, err == nil
+}
+
+func scrubTimestamps(s string) string {
+	return timestampPattern.ReplaceAllString(s, scrubbedTimestamp)
+}
@@ -8,13 +8,18 @@ package accountclient
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/afex/hystrix-go/hystrix"
@@ -30,6 +35,9 @@ const (
 	hystrixCommandName = "account-client"
 	// its threshold measured int percentages of errors in all requests which tells circuit breaker to open
 	defaultHystrixErrorPercentageThreshold = 30
+	// timeouts are enforced with context of each call, see sendRequest, so timer of circuit breaker mustn't fire first.
+	// Calls which time out are still counted as circuit breaker errors
+	hystrixTimeout = math.MaxInt32
 )
 
 // Client which performs rest api operations
@@ -40,7 +48,21 @@ const (
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
-	retrier    retrier
+	// commandName is name of circuit breaker, shared by clients with the same concurrency limit
+	commandName string
+	// idleClients are http clients without middlewares whose idle connections are closed by Close
+	idleClients    []*http.Client
+	retrier        retrier
+	defaultHeaders http.Header
+	cache          Cache
+	rateLimiter    *tokenBucket
+	bulkhead       *bulkhead
+	hedger         *hedger
+	endpoints      *endpointPool
+	lifecycle      lifecycle
+	// cacheGeneration is incremented on every cache invalidation
+	cacheGeneration atomic.Int64
+	fetches         fetchGroup
 }
 
 // NewAccountClient creates Client - we have to pass baseURL which has no default value as fake account api has no permanent address
@@ -62,19 +84,49 @@ func NewAccountClient(baseURL string, options ...ClientOption) (*Client, error)
 	for _, option := range options {
 		option(&cfg)
 	}
+	if cfg.err != nil {
+		return nil, fmt.Errorf("invalid client option provided: %w", cfg.err)
+	}
+	cfg.HTTPClient, err = applyTLSConfig(cfg.HTTPClient, cfg.TLSConfig)
+	if err != nil {
+		return nil, err
+	}
+	// transports wrapped by middlewares can't close idle connections, so clients with base transport are kept for Close
+	idleClients := []*http.Client{cfg.HTTPClient}
+	for _, tokenSource := range cfg.tokenSources {
+		tokenSource.httpClient = tokenHTTPClient(cfg.HTTPClient)
+		idleClients = append(idleClients, tokenSource.httpClient)
+	}
+	cfg.HTTPClient = applyMiddlewares(cfg.HTTPClient, cfg.Middlewares)
 
-	hystrix.ConfigureCommand(hystrixCommandName, hystrix.CommandConfig{
+	commandName := hystrixCommandName
+	hystrixConfig := hystrix.CommandConfig{
 		ErrorPercentThreshold: defaultHystrixErrorPercentageThreshold,
-		Timeout:               int(cfg.HTTPClient.Timeout.Milliseconds()),
-	})
+		Timeout:               hystrixTimeout,
+	}
+	if cfg.MaxConcurrency != nil {
+		// concurrency is limited by client, circuit breaker mustn't reject requests which got a slot. Circuit breaker
+		// sizes its pool only once, when it's used for the first time, so every limit gets its own command
+		hystrixConfig.MaxConcurrentRequests = cfg.MaxConcurrency.Reads + cfg.MaxConcurrency.Writes
+		commandName = fmt.Sprintf("%s-%d", hystrixCommandName, hystrixConfig.MaxConcurrentRequests)
+	}
+	hystrix.ConfigureCommand(commandName, hystrixConfig)
 
 	return &Client{
-		baseURL:    baseURL,
-		httpClient: cfg.HTTPClient,
+		baseURL:     baseURL,
+		httpClient:  cfg.HTTPClient,
+		commandName: commandName,
+		idleClients: idleClients,
 		retrier: retrier{
 			retryPolicy: cfg.RetryPolicy,
 			backoff:     cfg.BackoffStrategy,
 		},
+		defaultHeaders: defaultHeaders(cfg),
+		cache:          cfg.Cache,
+		rateLimiter:    newRateLimiter(cfg.RateLimit),
+		bulkhead:       newBulkhead(cfg.MaxConcurrency),
+		hedger:         newHedger(cfg.Hedging),
+		endpoints:      newEndpointPool(baseURL, cfg.Endpoints, cfg.HTTPClient),
 	}, nil
 }
 
@@ -89,6 +141,35 @@ type ClientConfig struct {
 	RetryPolicy RetryPolicy
 	// BackoffStrategy allows to defined strategy to make delays between next retries
 	BackoffStrategy BackoffStrategy
+	// TLSConfig is set on a clone of HTTPClient transport. It allows to configure mutual TLS and custom root CAs
+	TLSConfig *tls.Config
+	// UserAgent is sent in User-Agent header with every request
+	UserAgent string
+	// DefaultHeaders are sent with every request
+	DefaultHeaders http.Header
+	// Middlewares wrap transport of HTTPClient and are applied on every request attempt
+	Middlewares []Middleware
+	// Cache is used by FetchAccount to read accounts through it, see WithCache
+	Cache Cache
+	// RateLimit limits number of requests sent by Client, see WithRateLimit
+	RateLimit *RateLimit
+	// MaxConcurrency limits number of requests sent by Client at the same time, see WithMaxConcurrency
+	MaxConcurrency *MaxConcurrency
+	// Hedging makes FetchAccount send second request when the first one is slow, see WithHedging
+	Hedging *Hedging
+	// Endpoints are additional base URLs of account api, see WithEndpoints
+	Endpoints *Endpoints
+
+	// tokenSources get http.Client with TLS options applied, see WithOAuth2ClientCredentials
+	tokenSources []*clientCredentialsTokenSource
+	// err is the first error reported by ClientOption. It is returned from NewAccountClient
+	err error
+}
+
+func (cfg *ClientConfig) setErr(err error) {
+	if cfg.err == nil {
+		cfg.err = err
+	}
 }
 
 // WithRetriesOnDefaultRetryPolicy is a predefined DefaultRetryPolicy to use in NewAccountClient
@@ -140,7 +221,14 @@ func WithLinearBackoffStrategy(delay time.Duration) ClientOption {
 // If there will be 4xx or 500x error it can be in a form of RequestError, but currently not all 4xx errors are in the same format
 // In that case error msg will remain empty and only status code will be available
 // Other errors are returned as simple errors
-func (c *Client) CreateAccount(ctx context.Context, accountData *models.CreateAccountRequest) (*models.AccountResponse, error) {
+// CallOption can be used to modify behaviour of this single call
+func (c *Client) CreateAccount(ctx context.Context, accountData *models.CreateAccountRequest, options ...CallOption) (*models.AccountResponse, error) {
+	ctx, end, err := c.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
 	reqBody, err := json.Marshal(accountData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize account body: %w", err)
@@ -151,8 +239,12 @@ func (c *Client) CreateAccount(ctx context.Context, accountData *models.CreateAc
 		return nil, fmt.Errorf("failed to create a request to create a new account: %w", err)
 	}
 
+	if accountData != nil && accountData.Data != nil {
+		defer c.invalidateCached(accountData.Data.ID)
+	}
+
 	var accountResponse models.AccountResponse
-	err = c.sendRequest(ctx, request, &accountResponse)
+	err = c.sendRequest(ctx, request, &accountResponse, options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send create account request: %w", err)
 	}
@@ -164,7 +256,47 @@ func (c *Client) CreateAccount(ctx context.Context, accountData *models.CreateAc
 // If there will be 4xx or 500x error it can be in a form of RequestError, but currently not all 4xx errors are in the same format
 // In that case error msg will remain empty and only status code will be available
 // Other errors are returned as simple errors
-func (c *Client) FetchAccount(ctx context.Context, accountID uuid.UUID) (account *models.AccountResponse, err error) {
+// CallOption can be used to modify behaviour of this single call
+// When Client has been created WithCache, account is returned from cache if it's there
+// Concurrent calls fetching the same account without CallOption share a single request, see fetchGroup
+func (c *Client) FetchAccount(ctx context.Context, accountID uuid.UUID, options ...CallOption) (account *models.AccountResponse, err error) {
+	ctx, end, err := c.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
+	if !newCallConfig(options).skipCache {
+		if cached, ok := c.fetchCached(accountID); ok {
+			return cached, nil
+		}
+	}
+	if len(options) > 0 {
+		return c.fetchAccount(ctx, accountID, options)
+	}
+	return c.fetches.do(ctx, accountID, func(ctx context.Context) (*models.AccountResponse, error) {
+		return c.fetchAccount(ctx, accountID, nil)
+	})
+}
+
+func (c *Client) fetchAccount(ctx context.Context, accountID uuid.UUID, options []CallOption) (*models.AccountResponse, error) {
+	generation := c.cacheGeneration.Load()
+
+	var account *models.AccountResponse
+	var err error
+	if c.hedger != nil {
+		account, err = c.hedgedFetch(ctx, accountID, options)
+	} else {
+		account, err = c.sendFetch(ctx, accountID, options)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.storeCached(accountID, account, generation)
+	return account, nil
+}
+
+func (c *Client) sendFetch(ctx context.Context, accountID uuid.UUID, options []CallOption) (*models.AccountResponse, error) {
 	request, err := http.NewRequest(http.MethodGet,
 		fmt.Sprintf("%s/organisation/accounts/%s", c.baseURL, accountID.String()), http.NoBody)
 	if err != nil {
@@ -172,7 +304,7 @@ func (c *Client) FetchAccount(ctx context.Context, accountID uuid.UUID) (account
 	}
 
 	var accountResponse models.AccountResponse
-	err = c.sendRequest(ctx, request, &accountResponse)
+	err = c.sendRequest(ctx, request, &accountResponse, options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send fetch account request: %w", err)
 	}
@@ -184,15 +316,24 @@ func (c *Client) FetchAccount(ctx context.Context, accountID uuid.UUID) (account
 // If there will be 4xx or 500x error it can be in a form of RequestError, but currently not all 4xx errors are in the same format
 // In that case error msg will remain empty and only status code will be available
 // Other errors are returned as simple errors
-func (c *Client) DeleteAccount(ctx context.Context, accountID uuid.UUID, version *int64) error {
+// CallOption can be used to modify behaviour of this single call
+func (c *Client) DeleteAccount(ctx context.Context, accountID uuid.UUID, version *int64, options ...CallOption) error {
+	ctx, end, err := c.lifecycle.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer end()
+
 	request, err := http.NewRequest(http.MethodDelete,
 		fmt.Sprintf("%s/organisation/accounts/%s?version=%d", c.baseURL, accountID, *version),
 		http.NoBody)
 	if err != nil {
 		return fmt.Errorf("failed to delete account request: %w", err)
 	}
+	// cached account is removed also when delete fails, as i.e. conflict means that its version is outdated
+	defer c.invalidateCached(accountID)
 
-	err = c.sendRequest(ctx, request, nil)
+	err = c.sendRequest(ctx, request, nil, options)
 	if err != nil {
 		return fmt.Errorf("failed to send delete account request: %w", err)
 	}
@@ -200,16 +341,83 @@ func (c *Client) DeleteAccount(ctx context.Context, accountID uuid.UUID, version
 	return nil
 }
 
-func (c *Client) sendRequest(ctx context.Context, request *http.Request, result interface{}) error {
+func (c *Client) sendRequest(ctx context.Context, request *http.Request, result interface{}, options []CallOption) error {
+	ctx, end, err := c.lifecycle.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer end()
+
+	callCfg := newCallConfig(options)
+
+	// time spent in queue of rate limiter doesn't count into call timeout nor errors of circuit breaker,
+	// next attempts wait before retries
+	if c.rateLimiter != nil {
+		if err = c.rateLimiter.wait(ctx); err != nil {
+			return fmt.Errorf("failed to wait for rate limit: %w", err)
+		}
+	}
+
+	// request is cancelled when call returns, so nothing is sent on its behalf afterwards
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	httpClient := c.httpClient
+	timeout := callCfg.timeout
+	if timeout > 0 {
+		// http.Client is copied to not affect other calls which are using client wide timeout
+		callHTTPClient := *c.httpClient
+		callHTTPClient.Timeout = callCfg.timeout
+		httpClient = &callHTTPClient
+	} else if !callCfg.skipCircuitBreaker {
+		// calls protected by circuit breaker, including retries, have to finish within client timeout
+		timeout = c.httpClient.Timeout
+	}
+	if timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+		defer cancelTimeout()
+	}
+
+	callRetrier := c.retrier
+	if callCfg.retryPolicy != nil {
+		callRetrier.retryPolicy = callCfg.retryPolicy
+	}
+
 	request = request.WithContext(ctx)
-	setContentType(request)
+	c.setHeaders(request, callCfg)
+
+	if c.bulkhead != nil {
+		release, err := c.bulkhead.acquire(ctx, request.Method)
+		if err != nil {
+			return fmt.Errorf("failed to send request to an api: %w", err)
+		}
+		defer release()
+	}
+
+	// slot of bulkhead is released only when send has finished, also when circuit breaker has returned earlier
+	guard := newSendGuard()
+	defer func() {
+		cancel()
+		guard.wait()
+	}()
 
 	var resBody []byte
-	err := hystrix.Do(hystrixCommandName, func() error {
-		body, err := c.sendRequestWithRetries(request)
+	send := func() error {
+		body, err := c.sendRequestWithRetries(request, httpClient, callRetrier)
 		resBody = body
+		if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+			// circuit breaker doesn't count cancelled requests as errors, timeouts are still counted
+			return ctx.Err()
+		}
 		return err
-	}, nil)
+	}
+
+	if callCfg.skipCircuitBreaker {
+		err = guard.run(send)
+	} else {
+		err = hystrix.Do(c.commandName, func() error { return guard.run(send) }, nil)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to send request to an api: %w", err)
 	}
@@ -223,9 +431,72 @@ func (c *Client) sendRequest(ctx context.Context, request *http.Request, result
 	return nil
 }
 
-func (c *Client) sendRequestWithRetries(request *http.Request) ([]byte, error) {
-	res, err := c.retrier.retry(request, func(req *http.Request) (*http.Response, error) {
-		response, resErr := c.httpClient.Do(request)
+// sendGuard lets send start only until call returns and makes call wait for send which has started,
+// as circuit breaker doesn't stop send when it returns earlier
+type sendGuard struct {
+	mu       sync.Mutex
+	returned bool
+	started  bool
+	finished chan struct{}
+}
+
+func newSendGuard() *sendGuard {
+	return &sendGuard{finished: make(chan struct{})}
+}
+
+// run calls send unless call has already returned
+func (g *sendGuard) run(send func() error) error {
+	g.mu.Lock()
+	if g.returned {
+		g.mu.Unlock()
+		return context.Canceled
+	}
+	g.started = true
+	g.mu.Unlock()
+
+	defer close(g.finished)
+	return send()
+}
+
+// wait prevents send from starting and waits until send which has already started finishes
+func (g *sendGuard) wait() {
+	g.mu.Lock()
+	g.returned = true
+	started := g.started
+	g.mu.Unlock()
+
+	if started {
+		<-g.finished
+	}
+}
+
+func (c *Client) sendRequestWithRetries(request *http.Request, httpClient *http.Client, retrier retrier) ([]byte, error) {
+	var relativeURL string
+	if c.endpoints != nil {
+		relativeURL = c.endpoints.relativeURL(request)
+	}
+	attempt := 0
+	res, err := retrier.retry(request, func(req *http.Request) (*http.Response, error) {
+		attempt++
+		// the first attempt has waited for rate limit before it has been started
+		if c.rateLimiter != nil && attempt > 1 {
+			if waitErr := c.rateLimiter.wait(request.Context()); waitErr != nil {
+				return nil, fmt.Errorf("failed to wait for rate limit: %w", waitErr)
+			}
+		}
+		var endpointURL string
+		if c.endpoints != nil {
+			endpointURL = c.endpoints.pick()
+			if endpointErr := setEndpoint(request, endpointURL, relativeURL); endpointErr != nil {
+				return nil, endpointErr
+			}
+		}
+		response, resErr := httpClient.Do(request)
+		// cancelled requests, i.e. losers of hedging, don't say anything about health of endpoint,
+		// while timeouts are usual failures of hanging endpoints
+		if c.endpoints != nil && !errors.Is(request.Context().Err(), context.Canceled) {
+			c.endpoints.report(endpointURL, resErr != nil || response.StatusCode >= http.StatusInternalServerError)
+		}
 		if resErr != nil {
 			return nil, fmt.Errorf("failed to make request to an api : %w", resErr)
 		}
@@ -249,7 +520,7 @@ func (c *Client) sendRequestWithRetries(request *http.Request) ([]byte, error) {
 	}
 
 	if res.StatusCode >= http.StatusBadRequest {
-		return nil, c.reqErrFromResponse(resBody, res.StatusCode)
+		return nil, c.reqErrFromResponse(resBody, res.StatusCode, request.Header.Get(requestIDHeader))
 	}
 
 	return resBody, nil
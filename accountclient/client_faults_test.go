@@ -0,0 +1,157 @@
+package accountclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/accounttest"
+)
+
+func (s *accountAPIClientSuite) TestFaultTolerance() {
+	s.Run("should retry server errors and succeed when server recovers", func() {
+		// given
+		fakeAPI := accounttest.NewServer()
+		defer fakeAPI.Close()
+		accountsClient, err := NewAccountClient(fakeAPI.BaseURL(), WithRetriesOnDefaultRetryPolicy(3))
+		s.Require().NoError(err)
+		account, err := accountsClient.CreateAccount(context.Background(), createAccountRequest())
+		s.Require().NoError(err)
+		fakeAPI.InjectFault(accounttest.StatusFault(http.StatusInternalServerError, 2).WithMethod(http.MethodGet))
+		requestsBefore := fakeAPI.RequestCount()
+
+		// when
+		fetchedAccount, err := accountsClient.FetchAccount(context.Background(), account.Data.ID)
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(account.Data.ID, fetchedAccount.Data.ID)
+		s.Assert().Equal(3, fakeAPI.RequestCount()-requestsBefore)
+	})
+
+	s.Run("should apply backoff between retries of server errors", func() {
+		// given
+		fakeAPI := accounttest.NewServer()
+		defer fakeAPI.Close()
+		delay := time.Millisecond * 20
+		accountsClient, err := NewAccountClient(fakeAPI.BaseURL(),
+			WithRetriesOnDefaultRetryPolicy(2), WithLinearBackoffStrategy(delay))
+		s.Require().NoError(err)
+		fakeAPI.InjectFault(accounttest.StatusFault(http.StatusServiceUnavailable, 2))
+
+		// when
+		startTime := time.Now()
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New(), WithoutCircuitBreaker())
+
+		// then
+		var reqErr *RequestError
+		s.Require().ErrorAs(err, &reqErr)
+		s.Assert().Equal(http.StatusNotFound, reqErr.StatusCode)
+		s.Assert().GreaterOrEqual(time.Since(startTime), 2*delay)
+		s.Assert().Equal(3, fakeAPI.RequestCount())
+	})
+
+	s.Run("should not retry rate limited requests with default retry policy", func() {
+		// given
+		fakeAPI := accounttest.NewServer()
+		defer fakeAPI.Close()
+		accountsClient, err := NewAccountClient(fakeAPI.BaseURL(), WithRetriesOnDefaultRetryPolicy(3))
+		s.Require().NoError(err)
+		fakeAPI.InjectFault(accounttest.RateLimitFault(time.Second, 1))
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New(), WithoutCircuitBreaker())
+
+		// then
+		var reqErr *RequestError
+		s.Require().ErrorAs(err, &reqErr)
+		s.Assert().Equal(http.StatusTooManyRequests, reqErr.StatusCode)
+		s.Assert().Equal(1, fakeAPI.RequestCount())
+	})
+
+	s.Run("should return request error with raw body when error response is not json", func() {
+		// given
+		fakeAPI := accounttest.NewServer()
+		defer fakeAPI.Close()
+		accountsClient, err := NewAccountClient(fakeAPI.BaseURL())
+		s.Require().NoError(err)
+		fakeAPI.InjectFault(accounttest.NonJSONFault(http.StatusForbidden, "Forbidden", 1))
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New(), WithoutCircuitBreaker())
+
+		// then
+		var reqErr *RequestError
+		s.Require().ErrorAs(err, &reqErr)
+		s.Assert().Equal(http.StatusForbidden, reqErr.StatusCode)
+		s.Assert().Equal("Forbidden", reqErr.ErrMsg)
+	})
+
+	s.Run("should return error when response is malformed or connection is dropped", func() {
+		testCases := map[string]accounttest.Fault{
+			"malformed json":     accounttest.MalformedJSONFault(1),
+			"dropped connection": accounttest.DropConnectionFault(1),
+		}
+
+		for name, fault := range testCases {
+			s.Run(name, func() {
+				// given
+				fakeAPI := accounttest.NewServer()
+				defer fakeAPI.Close()
+				accountsClient, err := NewAccountClient(fakeAPI.BaseURL())
+				s.Require().NoError(err)
+				fakeAPI.InjectFault(fault)
+
+				// when
+				account, err := accountsClient.FetchAccount(context.Background(), uuid.New(), WithoutCircuitBreaker())
+
+				// then
+				s.Assert().Nil(account)
+				s.Assert().Error(err)
+				var reqErr *RequestError
+				s.Assert().False(errors.As(err, &reqErr))
+			})
+		}
+	})
+
+	s.Run("should time out delayed response", func() {
+		// given
+		fakeAPI := accounttest.NewServer()
+		defer fakeAPI.Close()
+		accountsClient, err := NewAccountClient(fakeAPI.BaseURL())
+		s.Require().NoError(err)
+		fakeAPI.InjectFault(accounttest.DelayFault(time.Second, 1))
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New(),
+			WithCallTimeout(time.Millisecond*20), WithoutCircuitBreaker())
+
+		// then
+		s.Assert().ErrorIs(err, context.DeadlineExceeded)
+	})
+
+	s.Run("should open circuit breaker when server keeps failing", func() {
+		// given
+		fakeAPI := accounttest.NewServer()
+		defer fakeAPI.Close()
+		defer hystrix.Flush()
+		accountsClient, err := NewAccountClient(fakeAPI.BaseURL())
+		s.Require().NoError(err)
+		fakeAPI.InjectFault(accounttest.StatusFault(http.StatusInternalServerError, 0))
+
+		// when
+		calls := 40
+		for i := 0; i < calls; i++ {
+			_, err = accountsClient.FetchAccount(context.Background(), uuid.New())
+			s.Require().Error(err)
+		}
+
+		// then
+		s.Assert().ErrorIs(err, hystrix.ErrCircuitOpen)
+		s.Assert().Less(fakeAPI.RequestCount(), calls)
+	})
+}
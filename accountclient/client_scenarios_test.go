@@ -0,0 +1,206 @@
+package accountclient
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/accounttest"
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+type accountApiClientIntegrationSuite struct {
+	suite.Suite
+
+	// baseURL of account api which scenarios are run against
+	baseURL          string
+	accountApiClient *Client
+}
+
+type CustomRetryPolicy struct {
+	maxRetries int
+}
+
+func (c CustomRetryPolicy) ShouldRetry(err error, response *http.Response) bool {
+	if response != nil {
+		return response.StatusCode >= http.StatusBadRequest
+	}
+	return false
+}
+
+func (c CustomRetryPolicy) NumberOfRetries() int {
+	return c.maxRetries
+}
+
+// TestAccountApiClientWithFakeAPI runs the same client scenarios as integration tests, but against in-process fake api,
+// so they don't need docker-compose to be run. Behaviour of api itself is verified by conformance package
+func TestAccountApiClientWithFakeAPI(t *testing.T) {
+	fakeAPI := accounttest.NewServer()
+	defer fakeAPI.Close()
+	suite.Run(t, &accountApiClientIntegrationSuite{baseURL: fakeAPI.BaseURL()})
+}
+
+func (s *accountApiClientIntegrationSuite) SetupSuite() {
+	s.accountApiClient = s.createAccountClient()
+}
+
+func (s *accountApiClientIntegrationSuite) AfterTest(_, _ string) {
+	hystrix.Flush()
+}
+
+func (s *accountApiClientIntegrationSuite) createAccountClient() *Client {
+	accountApiClient, err := NewAccountClient(s.baseURL,
+		WithRetriesOnDefaultRetryPolicy(3),
+		WithLinearBackoffStrategy(time.Millisecond*100))
+	if err != nil {
+		log.Fatal("failed to create account api client")
+	}
+	return accountApiClient
+}
+
+// client to test e2e retries and set more restrictive retry policy
+// where retries are triggered also on 4xx codes
+func (s *accountApiClientIntegrationSuite) customRetryPolicyAccountClient() *Client {
+	accountApiClient, err := NewAccountClient(s.baseURL,
+		WithCustomRetryPolicy(CustomRetryPolicy{3}), WithCustomHTTPClient(&http.Client{Timeout: time.Second * 60}))
+	if err != nil {
+		log.Fatal("failed to create account api client")
+	}
+	return accountApiClient
+}
+
+func (s *accountApiClientIntegrationSuite) TestCreateAccount() {
+	s.Run("should return error without error code when there is issue with request", func() {
+		// given
+		account := createAccountRequest()
+		s.accountApiClient.baseURL = "http://localhost:9999/fake/url/v1"
+		// when
+		accountResp, err := s.accountApiClient.CreateAccount(context.Background(), account)
+
+		// then
+		s.Assert().Nil(accountResp)
+		var reqErr *RequestError
+		s.Assert().False(errors.As(err, &reqErr))
+		s.Assert().NotNil(err)
+		s.accountApiClient = s.createAccountClient()
+	})
+}
+
+func (s *accountApiClientIntegrationSuite) TestFetchAccount() {
+	s.Run("should return error without error code when there is any issue with request", func() {
+		// given
+		accountID := uuid.New()
+		s.accountApiClient.baseURL = "http://localhost:9999/fake/url/v1"
+
+		// when
+		fetchedAccount, err := s.accountApiClient.FetchAccount(context.Background(), accountID)
+
+		// then
+		s.Assert().Nil(fetchedAccount)
+		var reqErr *RequestError
+		s.Assert().False(errors.As(err, &reqErr))
+		s.Assert().NotNil(err)
+		s.accountApiClient = s.createAccountClient()
+	})
+}
+
+func (s *accountApiClientIntegrationSuite) TestDeleteAccount() {
+	s.Run("should return error without error code when there is any issue with request", func() {
+		// given
+		accountID := uuid.New()
+		accountVersion := int64(0)
+		s.accountApiClient.baseURL = "http://localhost:9999/fake/url/v1"
+
+		// when
+		err := s.accountApiClient.DeleteAccount(context.Background(), accountID, &accountVersion)
+
+		// then
+		var reqErr *RequestError
+		s.Assert().False(errors.As(err, &reqErr))
+		s.Assert().NotNil(err)
+		s.accountApiClient = s.createAccountClient()
+	})
+}
+
+func (s *accountApiClientIntegrationSuite) TestRetriesAreApplied() {
+	// these tests actually check if after retries we are receiving request errors
+	// it has been created after issue where on retries nil request were sent
+	// thus url.Error was returned from function
+	s.Run("should retry failed requests and return request error", func() {
+		// given
+		account := createAccountRequest()
+		s.accountApiClient = s.customRetryPolicyAccountClient()
+		_, err := s.accountApiClient.CreateAccount(context.Background(), account)
+		s.Require().NoError(err)
+
+		// when
+		// it should be retried and finished with error because we are attempting to
+		// create account with the same id
+		_, err = s.accountApiClient.CreateAccount(context.Background(), account)
+
+		// then
+		s.Require().Error(err)
+		var reqErr *RequestError
+		s.Assert().ErrorAs(err, &reqErr)
+		s.Assert().Equal(reqErr.StatusCode, http.StatusConflict)
+	})
+
+	s.Run("should retry failed requests for fetching when we should have response body", func() {
+		// given
+		s.accountApiClient = s.customRetryPolicyAccountClient()
+
+		// when
+		// it should be retried and finished with error because we are attempting to
+		// create account with the same id
+		accountResp, err := s.accountApiClient.FetchAccount(context.Background(), uuid.New())
+
+		// then
+		s.Require().Error(err)
+		s.Assert().Nil(accountResp)
+		var reqErr *RequestError
+		s.Assert().ErrorAs(err, &reqErr)
+		s.Assert().Equal(reqErr.StatusCode, http.StatusNotFound)
+	})
+}
+
+func createAccountRequest() *models.CreateAccountRequest {
+	accountID := uuid.New()
+	organizationID := uuid.New()
+	version := new(int64)
+	*version = 0
+	accountClassification := "Personal"
+	accountMatchingOptOut := false
+	country := "GB"
+	jointAccount := false
+
+	return &models.CreateAccountRequest{Data: &models.CreateAccountData{
+		Attributes: &models.CreateAccountAttributes{
+			AccountClassification:   &accountClassification, // enum ?
+			AccountMatchingOptOut:   &accountMatchingOptOut, // deprecated
+			AccountNumber:           "41426819",
+			AlternativeNames:        []string{"Sam Holder"},
+			BankID:                  "400300",
+			BankIDCode:              "GBDSC",
+			BaseCurrency:            "GBP",
+			Bic:                     "NWBKGB22",
+			Country:                 &country,
+			Iban:                    "GB11NWBK40030041426819", // generated if not provided
+			JointAccount:            &jointAccount,
+			Name:                    []string{"Samantha Holder"},
+			SecondaryIdentification: "A1B2C3D4",
+			Status:                  nil, // Status of the account. pending and confirmed are set by Form3, closed can be set manually. Test creating closed account
+			Switched:                nil, // deprecated, account switched away from organization
+		},
+		ID:             accountID,
+		OrganisationID: organizationID,
+		Type:           "accounts",
+		Version:        version, // incremented witch each update, probably not needed in create
+	}}
+}
@@ -2,10 +2,8 @@ package accountclient
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"net/http"
-	"net/http/httptest"
 	"net/url"
 	"testing"
 	"time"
@@ -14,7 +12,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/suite"
 
-	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+	"github.com/arturskrzydlo/account-api-client/accountclient/accounttest"
 )
 
 type accountAPIClientSuite struct {
@@ -81,15 +79,12 @@ func (s *accountAPIClientSuite) TestAccountClientCreation() {
 func (s *accountAPIClientSuite) TestRetryPolicies() {
 	s.Run("client should retry request to an api according to retry policy defined in client config", func() {
 		// given
-		numCalls := 0
-		testServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			numCalls++
-		}))
+		fakeAPI := accounttest.NewServer()
+		defer fakeAPI.Close()
+		fakeAPI.InjectFault(accounttest.StatusFault(http.StatusInternalServerError, 0))
 
 		maxRetries := 3
-		accountsClient, err := NewAccountClient(testServ.URL, WithRetriesOnDefaultRetryPolicy(maxRetries))
+		accountsClient, err := NewAccountClient(fakeAPI.BaseURL(), WithRetriesOnDefaultRetryPolicy(maxRetries))
 		s.Assert().NoError(err)
 
 		// when
@@ -99,40 +94,28 @@ func (s *accountAPIClientSuite) TestRetryPolicies() {
 		var reqErr *RequestError
 		s.Assert().True(errors.As(err, &reqErr))
 		s.Assert().Equal(http.StatusInternalServerError, reqErr.StatusCode)
-		s.Assert().Equal(maxRetries+1, numCalls)
+		s.Assert().Equal(maxRetries+1, fakeAPI.RequestCount())
 	})
 
 	s.Run("client should retry request to an api according to retry policy and back to valid response after second retry", func() {
 		// given
 		maxRetries := 2
-		numCalls := 0
-		testServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if numCalls < maxRetries-1 {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusInternalServerError)
-			} else {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusOK)
-				response, _ := json.Marshal(models.AccountResponse{Data: &models.AccountDataResponse{
-					Attributes:     nil,
-					ID:             uuid.New(),
-					OrganisationID: uuid.New(),
-				}})
-				_, err := w.Write(response)
-				s.Require().NoError(err)
-			}
-			numCalls++
-		}))
-		accountsClient, err := NewAccountClient(testServ.URL, WithRetriesOnDefaultRetryPolicy(maxRetries))
+		fakeAPI := accounttest.NewServer()
+		defer fakeAPI.Close()
+		accountsClient, err := NewAccountClient(fakeAPI.BaseURL(), WithRetriesOnDefaultRetryPolicy(maxRetries))
 		s.Assert().NoError(err)
+		createdAccount, err := accountsClient.CreateAccount(context.Background(), createAccountRequest())
+		s.Require().NoError(err)
+		fakeAPI.InjectFault(accounttest.StatusFault(http.StatusInternalServerError, maxRetries-1))
+		requestsBefore := fakeAPI.RequestCount()
 
 		// when
-		account, err := accountsClient.FetchAccount(context.Background(), uuid.New())
+		account, err := accountsClient.FetchAccount(context.Background(), createdAccount.Data.ID)
 
 		// then
 		s.Require().NoError(err)
 		s.Assert().NotNil(account)
-		s.Assert().Equal(maxRetries, numCalls)
+		s.Assert().Equal(maxRetries, fakeAPI.RequestCount()-requestsBefore)
 	})
 
 	s.Run("test default retry policy", func() {
@@ -191,15 +174,14 @@ func (s *accountAPIClientSuite) TestBackoffStrategies() {
 	// to not make test last to long, it would require to add clock var and use it across client
 	s.Run("client should apply backoff strategy to retry", func() {
 		// given
-		testServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-		}))
+		fakeAPI := accounttest.NewServer()
+		defer fakeAPI.Close()
+		fakeAPI.InjectFault(accounttest.StatusFault(http.StatusInternalServerError, 0))
 
 		delay := time.Millisecond * 1
 		maxRetries := 3
 		multiplier := 10
-		accountsClient, err := NewAccountClient(testServ.URL,
+		accountsClient, err := NewAccountClient(fakeAPI.BaseURL(),
 			WithRetriesOnDefaultRetryPolicy(maxRetries),
 			WithExponentialBackoffStrategy(delay, multiplier))
 		s.Assert().NoError(err)
@@ -216,15 +198,14 @@ func (s *accountAPIClientSuite) TestBackoffStrategies() {
 
 	s.Run("applied backoff strategy should be reusable, so it should use the same strategy with initial values", func() {
 		// given
-		testServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-		}))
+		fakeAPI := accounttest.NewServer()
+		defer fakeAPI.Close()
+		fakeAPI.InjectFault(accounttest.StatusFault(http.StatusInternalServerError, 0))
 
 		delay := time.Millisecond * 1
 		maxRetries := 3
 		multiplier := 10
-		accountsClient, err := NewAccountClient(testServ.URL,
+		accountsClient, err := NewAccountClient(fakeAPI.BaseURL(),
 			WithRetriesOnDefaultRetryPolicy(maxRetries),
 			WithExponentialBackoffStrategy(delay, multiplier))
 		s.Assert().NoError(err)
@@ -278,12 +259,10 @@ func (s *accountAPIClientSuite) TestBackoffStrategies() {
 func (s *accountAPIClientSuite) TestClientCircuitBreaker() {
 	s.Run("should apply circuit breaker and not make any other api requests when reached error threshold", func() {
 		// given
-		numCalls := 0
-		testServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			numCalls++
-			http.Error(w, "server error", http.StatusInternalServerError)
-		}))
-		accountsClient, err := NewAccountClient(testServ.URL)
+		fakeAPI := accounttest.NewServer()
+		defer fakeAPI.Close()
+		fakeAPI.InjectFault(accounttest.StatusFault(http.StatusInternalServerError, 0))
+		accountsClient, err := NewAccountClient(fakeAPI.BaseURL())
 		s.Require().NoError(err)
 
 		// circuit breaker is configured for minimum 20 error calls to be opened
@@ -299,7 +278,7 @@ func (s *accountAPIClientSuite) TestClientCircuitBreaker() {
 		_, _ = accountsClient.FetchAccount(context.Background(), uuid.New())
 
 		// then
-		s.Assert().True(numCalls < serverCalls)
+		s.Assert().Less(fakeAPI.RequestCount(), serverCalls)
 
 		// cleanup
 		hystrix.Flush()
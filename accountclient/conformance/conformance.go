@@ -0,0 +1,288 @@
+// Package conformance provides test suite verifying that account api implementation behaves like accountclient expects
+//
+// Suite can be run against any implementation of account api: fake api delivered with docker-compose.yml,
+// in-process fake from accounttest package, custom stub servers or real api sandbox:
+//
+//	func TestStubServerConformance(t *testing.T) {
+//		conformance.Run(t, stubServer.URL+"/v1")
+//	}
+//
+// Each scenario creates accounts with new random ids, so suite can be run against api with existing data
+package conformance
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient"
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+type conformanceSuite struct {
+	suite.Suite
+
+	baseURL       string
+	clientOptions []accountclient.ClientOption
+	client        *accountclient.Client
+}
+
+// Run runs conformance suite against account api available under baseURL (i.e. http://localhost:8080/v1).
+// ClientOption can be used to configure client used by the suite, i.e. to authenticate requests.
+// Calls are made without circuit breaker, so failures of the suite don't affect other clients in the same process
+func Run(t *testing.T, baseURL string, options ...accountclient.ClientOption) {
+	t.Helper()
+	suite.Run(t, &conformanceSuite{baseURL: baseURL, clientOptions: options})
+}
+
+func (s *conformanceSuite) SetupSuite() {
+	client, err := accountclient.NewAccountClient(s.baseURL, s.clientOptions...)
+	s.Require().NoError(err)
+	s.client = client
+}
+
+func (s *conformanceSuite) createAccount(account *models.CreateAccountRequest) (*models.AccountResponse, error) {
+	return s.client.CreateAccount(context.Background(), account, accountclient.WithoutCircuitBreaker())
+}
+
+func (s *conformanceSuite) fetchAccount(accountID uuid.UUID) (*models.AccountResponse, error) {
+	return s.client.FetchAccount(context.Background(), accountID, accountclient.WithoutCircuitBreaker())
+}
+
+func (s *conformanceSuite) deleteAccount(accountID uuid.UUID, version *int64) error {
+	return s.client.DeleteAccount(context.Background(), accountID, version, accountclient.WithoutCircuitBreaker())
+}
+
+func (s *conformanceSuite) TestCreateAccount() {
+	s.Run("should successfully create single account", func() {
+		// given
+		account := NewCreateAccountRequest()
+
+		// when
+		accountResp, err := s.createAccount(account)
+
+		// then
+		s.Require().NoError(err)
+		s.assertCreatedAccount(account.Data, accountResp.Data)
+		fetchedAccount, err := s.fetchAccount(account.Data.ID)
+		s.Require().NoError(err)
+		s.assertCreatedAccount(account.Data, fetchedAccount.Data)
+	})
+
+	s.Run("should not create account with the same id twice", func() {
+		// given
+		account := NewCreateAccountRequest()
+		_, err := s.createAccount(account)
+		s.Require().NoError(err)
+
+		// when
+		accountResp, err := s.createAccount(account)
+
+		// then
+		s.Assert().Nil(accountResp)
+		s.assertRequestError(err, http.StatusConflict)
+	})
+
+	testCases := map[string]func(account *models.CreateAccountRequest){
+		"country":         func(account *models.CreateAccountRequest) { account.Data.Attributes.Country = nil },
+		"type":            func(account *models.CreateAccountRequest) { account.Data.Type = "" },
+		"organisation_id": func(account *models.CreateAccountRequest) { account.Data.OrganisationID = uuid.Nil },
+		"attributes":      func(account *models.CreateAccountRequest) { account.Data.Attributes = nil },
+	}
+	for field, removeField := range testCases {
+		s.Run("should not create account without "+field, func() {
+			// given
+			account := NewCreateAccountRequest()
+			removeField(account)
+
+			// when
+			accountResp, err := s.createAccount(account)
+
+			// then
+			s.Assert().Nil(accountResp)
+			reqErr := s.assertRequestError(err, http.StatusBadRequest)
+			if reqErr != nil {
+				s.Assert().NotEmpty(reqErr.ErrMsg)
+			}
+			_, err = s.fetchAccount(account.Data.ID)
+			s.assertRequestError(err, http.StatusNotFound)
+		})
+	}
+}
+
+func (s *conformanceSuite) TestFetchAccount() {
+	s.Run("should successfully fetch single account", func() {
+		// given
+		account := NewCreateAccountRequest()
+		_, err := s.createAccount(account)
+		s.Require().NoError(err)
+
+		// when
+		fetchedAccount, err := s.fetchAccount(account.Data.ID)
+
+		// then
+		s.Require().NoError(err)
+		s.assertCreatedAccount(account.Data, fetchedAccount.Data)
+	})
+
+	s.Run("should return not found when there is no account for given id", func() {
+		// when
+		fetchedAccount, err := s.fetchAccount(uuid.New())
+
+		// then
+		s.Assert().Nil(fetchedAccount)
+		s.assertRequestError(err, http.StatusNotFound)
+	})
+}
+
+func (s *conformanceSuite) TestDeleteAccount() {
+	s.Run("should successfully delete single account", func() {
+		// given
+		account := NewCreateAccountRequest()
+		accountResp, err := s.createAccount(account)
+		s.Require().NoError(err)
+
+		// when
+		err = s.deleteAccount(account.Data.ID, accountResp.Data.Version)
+
+		// then
+		s.Require().NoError(err)
+		fetchedAccount, err := s.fetchAccount(account.Data.ID)
+		s.Assert().Nil(fetchedAccount)
+		s.assertRequestError(err, http.StatusNotFound)
+	})
+
+	s.Run("should not delete account when version doesn't match", func() {
+		// given
+		account := NewCreateAccountRequest()
+		accountResp, err := s.createAccount(account)
+		s.Require().NoError(err)
+		invalidVersion := *accountResp.Data.Version + 1
+
+		// when
+		err = s.deleteAccount(account.Data.ID, &invalidVersion)
+
+		// then
+		s.assertRequestError(err, http.StatusConflict)
+		_, err = s.fetchAccount(account.Data.ID)
+		s.Assert().NoError(err)
+	})
+
+	s.Run("should return not found with empty message when there is no account for given id", func() {
+		// given
+		accountVersion := int64(0)
+
+		// when
+		err := s.deleteAccount(uuid.New(), &accountVersion)
+
+		// then
+		reqErr := s.assertRequestError(err, http.StatusNotFound)
+		if reqErr != nil {
+			s.Assert().Empty(reqErr.ErrMsg)
+		}
+	})
+}
+
+func (s *conformanceSuite) TestSampleAccountFlow() {
+	s.Run("should create account, then fetch it and at the end it should successfully delete it", func() {
+		// given
+		account := NewCreateAccountRequest()
+
+		// when creating account
+		accountResp, err := s.createAccount(account)
+
+		// then
+		s.Require().NoError(err)
+		s.assertCreatedAccount(account.Data, accountResp.Data)
+
+		// when fetching created account
+		fetchedAccount, err := s.fetchAccount(account.Data.ID)
+
+		// then
+		s.Require().NoError(err)
+		s.assertCreatedAccount(account.Data, fetchedAccount.Data)
+
+		// when deleting account
+		err = s.deleteAccount(fetchedAccount.Data.ID, fetchedAccount.Data.Version)
+
+		// then
+		s.Require().NoError(err)
+		fetchedAccount, err = s.fetchAccount(account.Data.ID)
+		s.Assert().Nil(fetchedAccount)
+		s.assertRequestError(err, http.StatusNotFound)
+	})
+}
+
+func (s *conformanceSuite) assertRequestError(err error, expectedStatusCode int) *accountclient.RequestError {
+	var reqErr *accountclient.RequestError
+	if !s.Assert().ErrorAs(err, &reqErr) {
+		return nil
+	}
+	s.Assert().Equal(expectedStatusCode, reqErr.StatusCode)
+	return reqErr
+}
+
+func (s *conformanceSuite) assertCreatedAccount(expectedAccount *models.CreateAccountData, actualAccount *models.AccountDataResponse) {
+	s.Require().NotNil(actualAccount)
+	s.Require().NotNil(actualAccount.Attributes)
+	s.Assert().Equal(expectedAccount.ID, actualAccount.ID)
+	s.Assert().Equal(expectedAccount.Type, actualAccount.Type)
+	s.Assert().Equal(expectedAccount.Version, actualAccount.Version)
+	s.Assert().Equal(expectedAccount.OrganisationID, actualAccount.OrganisationID)
+	// asserting account attributes
+	s.Assert().Equal(expectedAccount.Attributes.AccountClassification, actualAccount.Attributes.AccountClassification)
+	s.Assert().Equal(expectedAccount.Attributes.AccountMatchingOptOut, actualAccount.Attributes.AccountMatchingOptOut)
+	s.Assert().Equal(expectedAccount.Attributes.AccountNumber, actualAccount.Attributes.AccountNumber)
+	s.Assert().Equal(expectedAccount.Attributes.AlternativeNames, actualAccount.Attributes.AlternativeNames)
+	s.Assert().Equal(expectedAccount.Attributes.BankID, actualAccount.Attributes.BankID)
+	s.Assert().Equal(expectedAccount.Attributes.BankIDCode, actualAccount.Attributes.BankIDCode)
+	s.Assert().Equal(expectedAccount.Attributes.BaseCurrency, actualAccount.Attributes.BaseCurrency)
+	s.Assert().Equal(expectedAccount.Attributes.Bic, actualAccount.Attributes.Bic)
+	s.Assert().Equal(expectedAccount.Attributes.Country, actualAccount.Attributes.Country)
+	// iban is generated by api when it isn't provided
+	if expectedAccount.Attributes.Iban != "" {
+		s.Assert().Equal(expectedAccount.Attributes.Iban, actualAccount.Attributes.Iban)
+	}
+	s.Assert().Equal(expectedAccount.Attributes.JointAccount, actualAccount.Attributes.JointAccount)
+	s.Assert().Equal(expectedAccount.Attributes.Name, actualAccount.Attributes.Name)
+	s.Assert().Equal(expectedAccount.Attributes.SecondaryIdentification, actualAccount.Attributes.SecondaryIdentification)
+	s.Assert().Equal(expectedAccount.Attributes.Status, actualAccount.Attributes.Status)
+	s.Assert().Equal(expectedAccount.Attributes.Switched, actualAccount.Attributes.Switched)
+
+	s.Assert().False(actualAccount.CreatedOn.IsZero())
+	s.Assert().False(actualAccount.ModifiedOn.IsZero())
+}
+
+// NewCreateAccountRequest returns valid request to create account with new random account and organisation ids
+func NewCreateAccountRequest() *models.CreateAccountRequest {
+	version := int64(0)
+	accountClassification := "Personal"
+	accountMatchingOptOut := false
+	country := "GB"
+	jointAccount := false
+
+	return &models.CreateAccountRequest{Data: &models.CreateAccountData{
+		Attributes: &models.CreateAccountAttributes{
+			AccountClassification:   &accountClassification,
+			AccountMatchingOptOut:   &accountMatchingOptOut,
+			AccountNumber:           "41426819",
+			AlternativeNames:        []string{"Sam Holder"},
+			BankID:                  "400300",
+			BankIDCode:              "GBDSC",
+			BaseCurrency:            "GBP",
+			Bic:                     "NWBKGB22",
+			Country:                 &country,
+			Iban:                    "GB11NWBK40030041426819",
+			JointAccount:            &jointAccount,
+			Name:                    []string{"Samantha Holder"},
+			SecondaryIdentification: "A1B2C3D4",
+		},
+		ID:             uuid.New(),
+		OrganisationID: uuid.New(),
+		Type:           "accounts",
+		Version:        &version,
+	}}
+}
@@ -0,0 +1,13 @@
+//go:build integration
+
+package conformance
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestAccountAPIConformance(t *testing.T) {
+	Run(t, fmt.Sprintf("http://%s:8080/v1", os.Getenv("ACCOUNT_API_HOSTNAME")))
+}
@@ -0,0 +1,13 @@
+package conformance
+
+import (
+	"testing"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/accounttest"
+)
+
+func TestFakeAPIConformance(t *testing.T) {
+	fakeAPI := accounttest.NewServer()
+	defer fakeAPI.Close()
+	Run(t, fakeAPI.BaseURL())
+}
@@ -0,0 +1,288 @@
+package accountclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultEndpointFailureThreshold = 3
+	defaultEndpointProbeInterval    = 10 * time.Second
+	healthPath                      = "/health"
+)
+
+// EndpointStrategy decides which endpoint is used for a request, see WithEndpoints
+type EndpointStrategy int
+
+const (
+	// FailoverStrategy sends requests to the first healthy endpoint, so other endpoints are used only when
+	// the ones before them are unhealthy
+	FailoverStrategy EndpointStrategy = iota
+	// RoundRobinStrategy spreads requests evenly across healthy endpoints
+	RoundRobinStrategy
+)
+
+// Endpoints configures additional base URLs of account api, see WithEndpoints
+type Endpoints struct {
+	// URLs are base URLs used in addition to baseURL passed to NewAccountClient, which is the first endpoint
+	URLs []string
+	// Strategy is FailoverStrategy by default
+	Strategy EndpointStrategy
+	// FailureThreshold is number of consecutive failed attempts after which endpoint is unhealthy, 3 by default.
+	// Attempt fails when response can't be received or has 5xx status code
+	FailureThreshold int
+	// ProbeInterval is how often unhealthy endpoints are probed with GET request to their /health path, 10s by default.
+	// Endpoint is healthy again after successful probe
+	ProbeInterval time.Duration
+}
+
+// EndpointStatus describes health of an endpoint, see Client.Endpoints
+type EndpointStatus struct {
+	URL                 string
+	Healthy             bool
+	ConsecutiveFailures int
+}
+
+// WithEndpoints makes Client send requests to multiple base URLs of account api, i.e. in different regions.
+// Endpoint is chosen by Strategy before every attempt, so retries can go to another endpoint. Endpoints failing
+// repeatedly are marked unhealthy and skipped until they are healthy again. When all endpoints are unhealthy,
+// requests are still sent to them
+func WithEndpoints(endpoints Endpoints) ClientOption {
+	return func(cfg *ClientConfig) {
+		for _, endpointURL := range endpoints.URLs {
+			if _, err := url.ParseRequestURI(endpointURL); err != nil {
+				cfg.setErr(fmt.Errorf("invalid endpoint url provided: %w", err))
+				return
+			}
+		}
+		if endpoints.Strategy != FailoverStrategy && endpoints.Strategy != RoundRobinStrategy {
+			cfg.setErr(errors.New("unknown endpoint strategy"))
+			return
+		}
+		cfg.Endpoints = &endpoints
+	}
+}
+
+// Endpoints returns current health of endpoints in order of configuration. Client without WithEndpoints
+// has only one endpoint, which is always healthy
+func (c *Client) Endpoints() []EndpointStatus {
+	if c.endpoints == nil {
+		return []EndpointStatus{{URL: c.baseURL, Healthy: true}}
+	}
+	return c.endpoints.statuses()
+}
+
+type endpoint struct {
+	url      string
+	failures int
+	healthy  bool
+}
+
+// endpointPool chooses endpoints and tracks their health passively, based on results of requests
+type endpointPool struct {
+	strategy      EndpointStrategy
+	threshold     int
+	probeInterval time.Duration
+	probe         func(ctx context.Context, endpointURL string) error
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	next      int
+	probing   bool
+	stopped   bool
+	stop      chan struct{}
+}
+
+func newEndpointPool(baseURL string, endpoints *Endpoints, httpClient *http.Client) *endpointPool {
+	if endpoints == nil {
+		return nil
+	}
+	pool := &endpointPool{
+		strategy:      endpoints.Strategy,
+		threshold:     endpoints.FailureThreshold,
+		probeInterval: endpoints.ProbeInterval,
+		probe: func(ctx context.Context, endpointURL string) error {
+			return probeEndpoint(ctx, httpClient, endpointURL)
+		},
+		stop: make(chan struct{}),
+	}
+	if pool.threshold < 1 {
+		pool.threshold = defaultEndpointFailureThreshold
+	}
+	if pool.probeInterval <= 0 {
+		pool.probeInterval = defaultEndpointProbeInterval
+	}
+	for _, endpointURL := range append([]string{baseURL}, endpoints.URLs...) {
+		pool.endpoints = append(pool.endpoints, &endpoint{url: strings.TrimSuffix(endpointURL, "/"), healthy: true})
+	}
+	return pool
+}
+
+// pick returns base URL of endpoint for next attempt
+func (p *endpointPool) pick() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	candidates := make([]*endpoint, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		if e.healthy {
+			candidates = append(candidates, e)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = p.endpoints
+	}
+	if p.strategy == FailoverStrategy {
+		return candidates[0].url
+	}
+	chosen := candidates[p.next%len(candidates)]
+	p.next++
+	return chosen.url
+}
+
+// report records result of attempt sent to endpoint
+func (p *endpointPool) report(endpointURL string, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, e := range p.endpoints {
+		if e.url != endpointURL {
+			continue
+		}
+		if !failed {
+			e.failures = 0
+			e.healthy = true
+			return
+		}
+		e.failures++
+		if e.failures >= p.threshold && e.healthy {
+			e.healthy = false
+			if !p.probing && !p.stopped {
+				p.probing = true
+				go p.probeUnhealthy()
+			}
+		}
+		return
+	}
+}
+
+// probeUnhealthy probes unhealthy endpoints every probe interval until all endpoints are healthy
+func (p *endpointPool) probeUnhealthy() {
+	ticker := time.NewTicker(p.probeInterval)
+	defer ticker.Stop()
+	// cancels probe in progress when probing is stopped
+	probeCtx, cancelProbes := context.WithCancel(context.Background())
+	defer cancelProbes()
+	go func() {
+		select {
+		case <-p.stop:
+			cancelProbes()
+		case <-probeCtx.Done():
+		}
+	}()
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.stop:
+			return
+		}
+		for _, endpointURL := range p.unhealthy() {
+			ctx, cancel := context.WithTimeout(probeCtx, p.probeInterval)
+			if err := p.probe(ctx, endpointURL); err == nil {
+				p.report(endpointURL, false)
+			}
+			cancel()
+		}
+
+		p.mu.Lock()
+		if p.allHealthy() {
+			p.probing = false
+			p.mu.Unlock()
+			return
+		}
+		p.mu.Unlock()
+	}
+}
+
+// stopProbing stops probing of unhealthy endpoints, they are still marked healthy by successful requests
+func (p *endpointPool) stopProbing() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.stopped {
+		p.stopped = true
+		close(p.stop)
+	}
+}
+
+func (p *endpointPool) unhealthy() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	urls := make([]string, 0)
+	for _, e := range p.endpoints {
+		if !e.healthy {
+			urls = append(urls, e.url)
+		}
+	}
+	return urls
+}
+
+func (p *endpointPool) allHealthy() bool {
+	for _, e := range p.endpoints {
+		if !e.healthy {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *endpointPool) statuses() []EndpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	statuses := make([]EndpointStatus, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		statuses = append(statuses, EndpointStatus{URL: e.url, Healthy: e.healthy, ConsecutiveFailures: e.failures})
+	}
+	return statuses
+}
+
+// probeEndpoint sends GET request to health path of endpoint and expects 2xx status code
+func probeEndpoint(ctx context.Context, httpClient *http.Client, endpointURL string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpointURL+healthPath, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed to create health request: %w", err)
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to send health request: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("health request failed with status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// relativeURL returns part of request URL after base URL of the first endpoint, which is used to build requests
+func (p *endpointPool) relativeURL(request *http.Request) string {
+	return strings.TrimPrefix(request.URL.String(), p.endpoints[0].url)
+}
+
+// setEndpoint points request to endpoint, keeping its URL relative to base URL
+func setEndpoint(request *http.Request, endpointURL, relativeURL string) error {
+	endpointRequestURL, err := url.Parse(endpointURL + relativeURL)
+	if err != nil {
+		return fmt.Errorf("failed to build url of endpoint %s: %w", endpointURL, err)
+	}
+	request.URL = endpointRequestURL
+	request.Host = endpointRequestURL.Host
+	return nil
+}
@@ -0,0 +1,130 @@
+package accountclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// endpointServer counts account requests and responds to them with given status code, read on every request.
+// Health requests are answered with healthStatus
+func endpointServer(status, healthStatus *int32) (server *httptest.Server, requests *int32) {
+	requests = new(int32)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == healthPath {
+			w.WriteHeader(int(atomic.LoadInt32(healthStatus)))
+			return
+		}
+		atomic.AddInt32(requests, 1)
+		w.WriteHeader(int(atomic.LoadInt32(status)))
+		_, _ = w.Write([]byte(`{"data":{"type":"accounts"}}`))
+	}))
+	return server, requests
+}
+
+func (s *accountAPIClientSuite) TestEndpoints() {
+	s.Run("should fail over to secondary endpoint and probe primary until it's healthy", func() {
+		// given
+		primaryStatus, primaryHealth := int32(http.StatusInternalServerError), int32(http.StatusServiceUnavailable)
+		primary, primaryRequests := endpointServer(&primaryStatus, &primaryHealth)
+		defer primary.Close()
+		secondaryStatus := int32(http.StatusOK)
+		secondary, secondaryRequests := endpointServer(&secondaryStatus, &secondaryStatus)
+		defer secondary.Close()
+		accountsClient, err := NewAccountClient(primary.URL, WithRetriesOnDefaultRetryPolicy(1), WithEndpoints(Endpoints{
+			URLs: []string{secondary.URL}, FailureThreshold: 1, ProbeInterval: 10 * time.Millisecond,
+		}))
+		s.Require().NoError(err)
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New(), WithoutCircuitBreaker())
+		s.Require().NoError(err)
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New(), WithoutCircuitBreaker())
+		s.Require().NoError(err)
+
+		// then
+		s.Assert().Equal(int32(1), atomic.LoadInt32(primaryRequests))
+		s.Assert().Equal(int32(2), atomic.LoadInt32(secondaryRequests))
+		s.Assert().Equal([]EndpointStatus{
+			{URL: primary.URL, Healthy: false, ConsecutiveFailures: 1},
+			{URL: secondary.URL, Healthy: true},
+		}, accountsClient.Endpoints())
+
+		// when primary recovers
+		atomic.StoreInt32(&primaryStatus, http.StatusOK)
+		atomic.StoreInt32(&primaryHealth, http.StatusOK)
+
+		// then
+		s.Require().Eventually(func() bool {
+			return accountsClient.Endpoints()[0].Healthy
+		}, time.Second, 5*time.Millisecond)
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New(), WithoutCircuitBreaker())
+		s.Require().NoError(err)
+		s.Assert().Equal(int32(2), atomic.LoadInt32(primaryRequests))
+	})
+
+	s.Run("should fail over from endpoint which hangs until requests time out", func() {
+		// given
+		release := make(chan struct{})
+		primary, primaryRequests, _ := blockingServer(release)
+		defer primary.Close()
+		defer close(release)
+		secondaryStatus := int32(http.StatusOK)
+		secondary, secondaryRequests := endpointServer(&secondaryStatus, &secondaryStatus)
+		defer secondary.Close()
+		accountsClient, err := NewAccountClient(primary.URL,
+			WithCustomHTTPClient(&http.Client{Timeout: 100 * time.Millisecond}),
+			WithEndpoints(Endpoints{URLs: []string{secondary.URL}, FailureThreshold: 2, ProbeInterval: time.Minute}))
+		s.Require().NoError(err)
+
+		// when
+		for i := 0; i < 2; i++ {
+			_, err = accountsClient.FetchAccount(context.Background(), uuid.New())
+			s.Require().ErrorIs(err, context.DeadlineExceeded)
+		}
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New())
+
+		// then
+		s.Assert().NoError(err)
+		s.Assert().Equal(int32(2), atomic.LoadInt32(primaryRequests))
+		s.Assert().Equal(int32(1), atomic.LoadInt32(secondaryRequests))
+		s.Assert().Equal([]EndpointStatus{
+			{URL: primary.URL, Healthy: false, ConsecutiveFailures: 2},
+			{URL: secondary.URL, Healthy: true},
+		}, accountsClient.Endpoints())
+	})
+
+	s.Run("should spread requests across endpoints with round robin", func() {
+		// given
+		status := int32(http.StatusOK)
+		first, firstRequests := endpointServer(&status, &status)
+		defer first.Close()
+		second, secondRequests := endpointServer(&status, &status)
+		defer second.Close()
+		accountsClient, err := NewAccountClient(first.URL,
+			WithEndpoints(Endpoints{URLs: []string{second.URL}, Strategy: RoundRobinStrategy}))
+		s.Require().NoError(err)
+
+		// when
+		for i := 0; i < 4; i++ {
+			_, err = accountsClient.FetchAccount(context.Background(), uuid.New(), WithoutCircuitBreaker())
+			s.Require().NoError(err)
+		}
+
+		// then
+		s.Assert().Equal(int32(2), atomic.LoadInt32(firstRequests))
+		s.Assert().Equal(int32(2), atomic.LoadInt32(secondRequests))
+	})
+
+	s.Run("should not create client with invalid endpoint", func() {
+		// when
+		_, err := NewAccountClient("http://some-api.com", WithEndpoints(Endpoints{URLs: []string{"invalidURL"}}))
+
+		// then
+		s.Assert().Error(err)
+	})
+}
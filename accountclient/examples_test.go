@@ -59,3 +59,18 @@ func ExampleNewAccountClient_withOptions() {
 		WithLinearBackoffStrategy(time.Millisecond*100),
 		WithCustomHTTPClient(&http.Client{Timeout: time.Second * 20}))
 }
+
+func ExampleClient_FetchAccount_withCallOptions() {
+	client, err := NewAccountClient("localhost:8080")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = client.FetchAccount(context.Background(), uuid.New(),
+		WithCallTimeout(time.Second*2),
+		WithCallRetriesOnDefaultRetryPolicy(1),
+		WithHeader("X-Correlation-ID", "some-correlation-id"))
+	if err != nil {
+		log.Printf("failed to fetch an account: %s", err.Error())
+	}
+}
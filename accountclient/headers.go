@@ -0,0 +1,64 @@
+package accountclient
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	userAgentHeader = "User-Agent"
+)
+
+// WithUserAgent is a predefined option to set User-Agent header sent with every request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(cfg *ClientConfig) {
+		cfg.UserAgent = userAgent
+	}
+}
+
+// WithDefaultHeaders is a predefined option to set headers sent with every request. Headers can be overridden
+// for a single call with WithHeader. Calling it multiple times merges headers
+func WithDefaultHeaders(headers http.Header) ClientOption {
+	return func(cfg *ClientConfig) {
+		if cfg.DefaultHeaders == nil {
+			cfg.DefaultHeaders = make(http.Header)
+		}
+		for name, values := range headers {
+			cfg.DefaultHeaders[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+		}
+	}
+}
+
+// WithRequestID sets X-Request-ID header for a single call instead of generated one.
+// Request ID is sent on every retry of this call and returned in RequestError
+func WithRequestID(requestID string) CallOption {
+	return WithHeader(requestIDHeader, requestID)
+}
+
+func defaultHeaders(cfg ClientConfig) http.Header {
+	headers := make(http.Header)
+	for name, values := range cfg.DefaultHeaders {
+		headers[name] = append([]string(nil), values...)
+	}
+	if cfg.UserAgent != "" {
+		headers.Set(userAgentHeader, cfg.UserAgent)
+	}
+	return headers
+}
+
+// setHeaders sets on request client default headers and then call headers, so call headers take precedence.
+// Each request has X-Request-ID, which is generated when it hasn't been provided
+func (c *Client) setHeaders(request *http.Request, callCfg callConfig) {
+	setContentType(request)
+	for name, values := range c.defaultHeaders {
+		request.Header[name] = values
+	}
+	for name, values := range callCfg.headers {
+		request.Header[name] = values
+	}
+	if request.Header.Get(requestIDHeader) == "" {
+		request.Header.Set(requestIDHeader, uuid.New().String())
+	}
+}
@@ -0,0 +1,68 @@
+package accountclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/google/uuid"
+)
+
+func (s *accountAPIClientSuite) TestHeaders() {
+	s.Run("should send user agent, default headers and generated request id", func() {
+		// given
+		receivedHeaders := make([]http.Header, 0)
+		testServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			receivedHeaders = append(receivedHeaders, r.Header.Clone())
+			_, _ = w.Write([]byte(`{"data":{}}`))
+		}))
+		defer testServ.Close()
+
+		accountsClient, err := NewAccountClient(testServ.URL,
+			WithUserAgent("payments-service/1.0"),
+			WithDefaultHeaders(http.Header{"x-tenant": {"tenant-1"}, "X-Source": {"default"}}))
+		s.Require().NoError(err)
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New())
+		s.Require().NoError(err)
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New(), WithHeader("X-Source", "call"))
+		s.Require().NoError(err)
+
+		// then
+		s.Require().Len(receivedHeaders, 2)
+		for _, headers := range receivedHeaders {
+			s.Assert().Equal("payments-service/1.0", headers.Get("User-Agent"))
+			s.Assert().Equal("tenant-1", headers.Get("X-Tenant"))
+			_, err = uuid.Parse(headers.Get("X-Request-ID"))
+			s.Assert().NoError(err)
+		}
+		s.Assert().Equal("default", receivedHeaders[0].Get("X-Source"))
+		s.Assert().Equal("call", receivedHeaders[1].Get("X-Source"))
+		s.Assert().NotEqual(receivedHeaders[0].Get("X-Request-ID"), receivedHeaders[1].Get("X-Request-ID"))
+	})
+
+	s.Run("should send the same request id on retries and return it in request error", func() {
+		// given
+		requestIDs := make([]string, 0)
+		testServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestIDs = append(requestIDs, r.Header.Get("X-Request-ID"))
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer testServ.Close()
+
+		accountsClient, err := NewAccountClient(testServ.URL, WithRetriesOnDefaultRetryPolicy(2))
+		s.Require().NoError(err)
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New(),
+			WithRequestID("some-request-id"), WithoutCircuitBreaker())
+
+		// then
+		var reqErr *RequestError
+		s.Require().ErrorAs(err, &reqErr)
+		s.Assert().Equal("some-request-id", reqErr.RequestID)
+		s.Assert().Contains(reqErr.Error(), "some-request-id")
+		s.Assert().Equal([]string{"some-request-id", "some-request-id", "some-request-id"}, requestIDs)
+	})
+}
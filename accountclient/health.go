@@ -0,0 +1,131 @@
+package accountclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+)
+
+const (
+	// HealthStatusUp is status reported by healthy account api
+	HealthStatusUp = "up"
+	// HealthStatusDown is status of account api which couldn't be reached or responded with error
+	HealthStatusDown = "down"
+)
+
+// Health describes state of account api seen by Client
+type Health struct {
+	// Status reported by account api, HealthStatusDown when it couldn't be checked
+	Status string `json:"status"`
+	// Latency of health request, it's serialized to json as duration string, i.e. "1.5ms"
+	Latency time.Duration `json:"-"`
+	// CircuitOpen is true when circuit breaker of Client rejects requests
+	CircuitOpen bool `json:"circuit_open"`
+	// Error describes why health couldn't be checked
+	Error string `json:"error,omitempty"`
+}
+
+// MarshalJSON serializes Health with human-readable latency
+func (h *Health) MarshalJSON() ([]byte, error) {
+	type health Health
+	return json.Marshal(struct {
+		*health
+		Latency string `json:"latency"`
+	}{health: (*health)(h), Latency: h.Latency.String()})
+}
+
+// Ready returns true when account api is up and circuit breaker lets requests through
+func (h *Health) Ready() bool {
+	return h.Status == HealthStatusUp && !h.CircuitOpen
+}
+
+// Health checks health of account api with GET request to /health under base URL. The request isn't sent through
+// circuit breaker, so it's not rejected when circuit is open and doesn't affect circuit breaker statistics.
+// Health is returned also when api couldn't be reached, error is returned together with it in such case
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	health := &Health{Status: HealthStatusDown}
+	ctx, end, err := c.lifecycle.begin(ctx)
+	if err != nil {
+		// closed client isn't ready to serve requests
+		health.Error = err.Error()
+		return health, err
+	}
+	defer end()
+
+	if circuit, _, err := hystrix.GetCircuit(c.commandName); err == nil {
+		health.CircuitOpen = circuit.IsOpen()
+	}
+
+	baseURL := c.baseURL
+	if c.endpoints != nil {
+		baseURL = c.endpoints.pick()
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+healthPath, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create health request: %w", err)
+	}
+	c.setHeaders(request, newCallConfig(nil))
+
+	start := time.Now()
+	response, err := c.httpClient.Do(request)
+	health.Latency = time.Since(start)
+	if err != nil {
+		health.Error = err.Error()
+		return health, fmt.Errorf("failed to send health request: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		health.Error = err.Error()
+		return health, fmt.Errorf("failed to read health response: %w", err)
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		reqErr := c.reqErrFromResponse(body, response.StatusCode, request.Header.Get(requestIDHeader))
+		health.Error = reqErr.Error()
+		return health, fmt.Errorf("health request failed: %w", reqErr)
+	}
+	var status struct {
+		Status string `json:"status"`
+	}
+	if err = json.Unmarshal(body, &status); err != nil {
+		health.Error = err.Error()
+		return health, fmt.Errorf("failed to unmarshall health response: %w", err)
+	}
+	health.Status = status.Status
+	return health, nil
+}
+
+// HealthChecker checks health of account api. It's implemented by Client and by mock from accountmock package
+type HealthChecker interface {
+	Health(ctx context.Context) (*Health, error)
+}
+
+// HealthHandler returns http.Handler which can be mounted as readiness probe, i.e. in Kubernetes. It responds with
+// Health as json, with status code 200 when Health is ready and 503 otherwise. Health is checked within timeout
+func HealthHandler(client HealthChecker, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		statusCode := http.StatusOK
+		health, err := client.Health(ctx)
+		if health == nil {
+			health = &Health{Status: HealthStatusDown, Error: "health hasn't been checked"}
+			if err != nil {
+				health.Error = err.Error()
+			}
+		}
+		if !health.Ready() {
+			statusCode = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", jsonType)
+		w.WriteHeader(statusCode)
+		_ = json.NewEncoder(w).Encode(health)
+	})
+}
@@ -0,0 +1,114 @@
+package accountclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/accounttest"
+)
+
+// healthCheckerFunc is an adapter to use function as HealthChecker
+type healthCheckerFunc func(ctx context.Context) (*Health, error)
+
+func (f healthCheckerFunc) Health(ctx context.Context) (*Health, error) {
+	return f(ctx)
+}
+
+func (s *accountAPIClientSuite) TestHealth() {
+	defer hystrix.Flush()
+
+	s.Run("should report healthy api and closed circuit", func() {
+		// given
+		hystrix.Flush()
+		fakeAPI := accounttest.NewServer()
+		defer fakeAPI.Close()
+		accountsClient, err := NewAccountClient(fakeAPI.BaseURL())
+		s.Require().NoError(err)
+
+		// when
+		health, err := accountsClient.Health(context.Background())
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(HealthStatusUp, health.Status)
+		s.Assert().False(health.CircuitOpen)
+		s.Assert().Positive(health.Latency)
+		s.Assert().True(health.Ready())
+	})
+
+	s.Run("should report api which responds with error as down", func() {
+		// given
+		fakeAPI := accounttest.NewServer()
+		defer fakeAPI.Close()
+		fakeAPI.InjectFault(accounttest.StatusFault(http.StatusServiceUnavailable, 1))
+		accountsClient, err := NewAccountClient(fakeAPI.BaseURL())
+		s.Require().NoError(err)
+
+		// when
+		health, err := accountsClient.Health(context.Background())
+
+		// then
+		var reqErr *RequestError
+		s.Require().ErrorAs(err, &reqErr)
+		s.Assert().Equal(http.StatusServiceUnavailable, reqErr.StatusCode)
+		s.Assert().Equal(HealthStatusDown, health.Status)
+		s.Assert().NotEmpty(health.Error)
+		s.Assert().False(health.Ready())
+	})
+
+	s.Run("should serve readiness probe which fails when circuit is open", func() {
+		// given
+		hystrix.Flush()
+		fakeAPI := accounttest.NewServer()
+		defer fakeAPI.Close()
+		accountsClient, err := NewAccountClient(fakeAPI.BaseURL())
+		s.Require().NoError(err)
+		handler := HealthHandler(accountsClient, time.Second)
+
+		// when
+		ready := httptest.NewRecorder()
+		handler.ServeHTTP(ready, httptest.NewRequest(http.MethodGet, "/ready", http.NoBody))
+
+		// then
+		s.Assert().Equal(http.StatusOK, ready.Code)
+		var body map[string]interface{}
+		s.Require().NoError(json.Unmarshal(ready.Body.Bytes(), &body))
+		s.Assert().Equal(HealthStatusUp, body["status"])
+		s.Assert().Equal(false, body["circuit_open"])
+		s.Assert().NotEmpty(body["latency"])
+
+		// when circuit opens
+		fakeAPI.InjectFault(accounttest.StatusFault(http.StatusInternalServerError, 30))
+		for i := 0; i < 30; i++ {
+			_, _ = accountsClient.FetchAccount(context.Background(), uuid.New())
+		}
+		fakeAPI.ClearFaults()
+		notReady := httptest.NewRecorder()
+		handler.ServeHTTP(notReady, httptest.NewRequest(http.MethodGet, "/ready", http.NoBody))
+
+		// then
+		s.Assert().Equal(http.StatusServiceUnavailable, notReady.Code)
+		s.Assert().Contains(notReady.Body.String(), `"circuit_open":true`)
+	})
+
+	s.Run("should serve readiness probe which fails when checker returns no health and no error", func() {
+		// given
+		handler := HealthHandler(healthCheckerFunc(func(context.Context) (*Health, error) {
+			return nil, nil
+		}), time.Second)
+
+		// when
+		notReady := httptest.NewRecorder()
+		handler.ServeHTTP(notReady, httptest.NewRequest(http.MethodGet, "/ready", http.NoBody))
+
+		// then
+		s.Assert().Equal(http.StatusServiceUnavailable, notReady.Code)
+		s.Assert().Contains(notReady.Body.String(), `"status":"down"`)
+	})
+}
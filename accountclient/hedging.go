@@ -0,0 +1,141 @@
+package accountclient
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+const (
+	// number of the latest fetch latencies from which hedging delay is computed
+	hedgingSamples = 100
+	// minimum number of recorded latencies after which percentile is used instead of initial delay
+	hedgingMinSamples = 10
+)
+
+// Hedging configures hedged fetches, see WithHedging
+type Hedging struct {
+	// Percentile of recent FetchAccount latencies after which second request is sent, i.e. 95
+	Percentile float64
+	// InitialDelay is used until enough latencies have been recorded
+	InitialDelay time.Duration
+}
+
+// WithHedging makes FetchAccount send second identical request when the first one hasn't completed within percentile
+// of latencies of recent fetches, i.e. 95th. The first successful response is returned and the other request is
+// cancelled, cancelled requests aren't counted as circuit breaker errors. Until enough fetches have been made,
+// initialDelay is used instead of percentile. Only fetches are hedged, as they are the only idempotent reads
+func WithHedging(percentile float64, initialDelay time.Duration) ClientOption {
+	return func(cfg *ClientConfig) {
+		if percentile <= 0 || percentile >= 100 {
+			cfg.setErr(errors.New("hedging percentile must be between 0 and 100"))
+			return
+		}
+		if initialDelay <= 0 {
+			cfg.setErr(errors.New("hedging initial delay must be greater than 0"))
+			return
+		}
+		cfg.Hedging = &Hedging{Percentile: percentile, InitialDelay: initialDelay}
+	}
+}
+
+// hedger computes hedging delay from the latest latencies
+type hedger struct {
+	percentile   float64
+	initialDelay time.Duration
+
+	mu        sync.Mutex
+	latencies []time.Duration
+	next      int
+}
+
+func newHedger(hedging *Hedging) *hedger {
+	if hedging == nil {
+		return nil
+	}
+	return &hedger{
+		percentile:   hedging.Percentile,
+		initialDelay: hedging.InitialDelay,
+		latencies:    make([]time.Duration, 0, hedgingSamples),
+	}
+}
+
+func (h *hedger) record(latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.latencies) < hedgingSamples {
+		h.latencies = append(h.latencies, latency)
+		return
+	}
+	h.latencies[h.next] = latency
+	h.next = (h.next + 1) % hedgingSamples
+}
+
+func (h *hedger) delay() time.Duration {
+	h.mu.Lock()
+	latencies := append([]time.Duration(nil), h.latencies...)
+	h.mu.Unlock()
+
+	if len(latencies) < hedgingMinSamples {
+		return h.initialDelay
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	index := int(math.Ceil(h.percentile/100*float64(len(latencies)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return latencies[index]
+}
+
+type fetchResult struct {
+	account *models.AccountResponse
+	err     error
+}
+
+// hedgedFetch sends fetch and, when it hasn't completed within hedging delay, second one.
+// The first successful result is returned, error is returned when all sent fetches have failed
+func (c *Client) hedgedFetch(ctx context.Context, accountID uuid.UUID, options []CallOption) (*models.AccountResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	// cancels request which hasn't won
+	defer cancel()
+
+	start := time.Now()
+	results := make(chan fetchResult, 2)
+	fetch := func() {
+		account, err := c.sendFetch(ctx, accountID, options)
+		results <- fetchResult{account: account, err: err}
+	}
+	go fetch()
+
+	timer := time.NewTimer(c.hedger.delay())
+	defer timer.Stop()
+	hedge := timer.C
+	pending := 1
+	var lastErr error
+	for pending > 0 {
+		select {
+		case <-hedge:
+			hedge = nil
+			pending++
+			go fetch()
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				c.hedger.record(time.Since(start))
+				return result.account, nil
+			}
+			lastErr = result.err
+			// failed request isn't hedged, retries are configured with RetryPolicy
+			hedge = nil
+		}
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,101 @@
+package accountclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/google/uuid"
+)
+
+func (s *accountAPIClientSuite) TestHedging() {
+	defer hystrix.Flush()
+
+	s.Run("should compute delay from percentile of recorded latencies", func() {
+		// given
+		h := newHedger(&Hedging{Percentile: 95, InitialDelay: time.Second})
+		s.Require().Equal(time.Second, h.delay())
+
+		// when
+		for i := 1; i <= 200; i++ {
+			h.record(time.Duration(i) * time.Millisecond)
+		}
+
+		// then
+		// only the latest 100 latencies are used
+		s.Assert().Equal(195*time.Millisecond, h.delay())
+	})
+
+	s.Run("should return the first successful response and cancel slow request", func() {
+		// given
+		var requests int32
+		slowCancelled := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) == 1 {
+				<-r.Context().Done()
+				close(slowCancelled)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"type":"accounts"}}`))
+		}))
+		defer server.Close()
+		accountsClient, err := NewAccountClient(server.URL, WithHedging(95, 20*time.Millisecond))
+		s.Require().NoError(err)
+
+		// when
+		account, err := accountsClient.FetchAccount(context.Background(), uuid.New())
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal("accounts", account.Data.Type)
+		s.Assert().Equal(int32(2), atomic.LoadInt32(&requests))
+		select {
+		case <-slowCancelled:
+		case <-time.After(time.Second):
+			s.Fail("slow request hasn't been cancelled")
+		}
+	})
+
+	s.Run("should not hedge fast and failed requests", func() {
+		// given
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			if r.URL.Path == "/organisation/accounts/"+uuid.Nil.String() {
+				http.Error(w, `{"error_message":"not found"}`, http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"type":"accounts"}}`))
+		}))
+		defer server.Close()
+		accountsClient, err := NewAccountClient(server.URL, WithHedging(95, 50*time.Millisecond))
+		s.Require().NoError(err)
+
+		// when
+		_, fetchErr := accountsClient.FetchAccount(context.Background(), uuid.New())
+		_, missingErr := accountsClient.FetchAccount(context.Background(), uuid.Nil)
+		time.Sleep(60 * time.Millisecond)
+
+		// then
+		s.Assert().NoError(fetchErr)
+		s.Assert().Error(missingErr)
+		s.Assert().Equal(int32(2), atomic.LoadInt32(&requests))
+	})
+
+	s.Run("should not create client with invalid hedging", func() {
+		testCases := map[string]ClientOption{
+			"percentile out of range": WithHedging(100, time.Second),
+			"zero initial delay":      WithHedging(95, 0),
+		}
+		for name, option := range testCases {
+			// when
+			_, err := NewAccountClient("http://some-api.com", option)
+
+			// then
+			s.Assert().Error(err, name)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package accountclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+// AccountIDFromExternalRef derives account id from organisation id and reference of the account in caller's system,
+// i.e. customer number. It's UUIDv5 with organisationID as namespace, so the same reference always gives the same id
+func AccountIDFromExternalRef(organisationID uuid.UUID, externalRef string) uuid.UUID {
+	return uuid.NewSHA1(organisationID, []byte(externalRef))
+}
+
+// CreateAccountIdempotent creates account with id derived from its organisation id and externalRef,
+// see AccountIDFromExternalRef. When such account already exists, i.e. it has been created by previous attempt
+// whose response was lost, existing account is fetched and returned instead of failing. It's returned as it is,
+// attributes in accountData aren't compared with it. Id set in accountData is replaced, accountData isn't modified.
+// CallOption are applied to both create and fetch
+func (c *Client) CreateAccountIdempotent(ctx context.Context, externalRef string, accountData *models.CreateAccountRequest,
+	options ...CallOption,
+) (*models.AccountResponse, error) {
+	ctx, end, err := c.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
+	if externalRef == "" {
+		return nil, errors.New("external reference must not be empty")
+	}
+	if accountData == nil || accountData.Data == nil || accountData.Data.OrganisationID == uuid.Nil {
+		return nil, errors.New("organisation id is required to derive account id")
+	}
+
+	data := *accountData.Data
+	data.ID = AccountIDFromExternalRef(data.OrganisationID, externalRef)
+	account, err := c.CreateAccount(ctx, &models.CreateAccountRequest{Data: &data}, options...)
+	if err == nil {
+		return account, nil
+	}
+	if !errors.Is(classify(err, http.StatusConflict, ErrAccountExists), ErrAccountExists) {
+		return nil, err
+	}
+
+	// account created by previous attempt, cached account could be older than it
+	fetchOptions := append(append([]CallOption{}, options...), WithoutCache())
+	account, err = c.FetchAccount(ctx, data.ID, fetchOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch existing account %s: %w", data.ID, err)
+	}
+	return account, nil
+}
@@ -0,0 +1,69 @@
+package accountclient
+
+import (
+	"context"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/accounttest"
+)
+
+func (s *accountAPIClientSuite) TestAccountIDFromExternalRef() {
+	s.Run("should derive the same id from the same organisation and reference", func() {
+		// given
+		organisationID := uuid.New()
+
+		// when
+		accountID := AccountIDFromExternalRef(organisationID, "customer-1")
+
+		// then
+		s.Assert().Equal(accountID, AccountIDFromExternalRef(organisationID, "customer-1"))
+		s.Assert().Equal(uuid.Version(5), accountID.Version())
+		s.Assert().NotEqual(accountID, AccountIDFromExternalRef(organisationID, "customer-2"))
+		s.Assert().NotEqual(accountID, AccountIDFromExternalRef(uuid.New(), "customer-1"))
+	})
+}
+
+func (s *accountAPIClientSuite) TestCreateAccountIdempotent() {
+	defer hystrix.Flush()
+
+	s.Run("should create account with derived id and return it when created again", func() {
+		// given
+		fakeAPI := accounttest.NewServer()
+		defer fakeAPI.Close()
+		accountsClient, err := NewAccountClient(fakeAPI.BaseURL())
+		s.Require().NoError(err)
+		request := createAccountRequest()
+		requestedID := request.Data.ID
+		expectedID := AccountIDFromExternalRef(request.Data.OrganisationID, "customer-1")
+
+		// when
+		created, err := accountsClient.CreateAccountIdempotent(context.Background(), "customer-1", request,
+			WithoutCircuitBreaker())
+		s.Require().NoError(err)
+		again, err := accountsClient.CreateAccountIdempotent(context.Background(), "customer-1", request,
+			WithoutCircuitBreaker())
+		s.Require().NoError(err)
+
+		// then
+		s.Assert().Equal(expectedID, created.Data.ID)
+		s.Assert().Equal(expectedID, again.Data.ID)
+		s.Assert().Equal(created.Data.Version, again.Data.Version)
+		s.Assert().Equal(requestedID, request.Data.ID)
+	})
+
+	s.Run("should return error when organisation id is missing", func() {
+		// given
+		accountsClient, err := NewAccountClient("http://localhost:8080/v1")
+		s.Require().NoError(err)
+		request := createAccountRequest()
+		request.Data.OrganisationID = uuid.Nil
+
+		// when
+		_, err = accountsClient.CreateAccountIdempotent(context.Background(), "customer-1", request)
+
+		// then
+		s.Assert().Error(err)
+	})
+}
@@ -0,0 +1,79 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// checkpoint stores ids of accounts which have been already imported. It's append only file with one id in each line,
+// so when import crashes, only the line which was being written can be lost and it's skipped on load
+type checkpoint struct {
+	mu   sync.Mutex
+	file *os.File
+	done map[uuid.UUID]bool
+}
+
+// openCheckpoint loads ids from checkpoint file under path and opens it to append new ones. File is created if it doesn't exist
+func openCheckpoint(path string) (*checkpoint, error) {
+	done := make(map[uuid.UUID]bool)
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		if id, parseErr := uuid.Parse(strings.TrimSpace(scanner.Text())); parseErr == nil {
+			done[id] = true
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	// torn last line of crashed import is terminated, so the next id is written in its own line
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		if _, err = file.WriteString("\n"); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to write checkpoint: %w", err)
+		}
+	}
+	return &checkpoint{file: file, done: done}, nil
+}
+
+func (c *checkpoint) isDone(id uuid.UUID) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done[id]
+}
+
+// markDone appends id to checkpoint file. It's written directly to the file, so it survives crash of the process
+func (c *checkpoint) markDone(id uuid.UUID) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.file.WriteString(id.String() + "\n"); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	c.done[id] = true
+	return nil
+}
+
+func (c *checkpoint) close() error {
+	if c == nil {
+		return nil
+	}
+	return c.file.Close()
+}
@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+// FieldDifference is field set in desired account whose value is different in existing account.
+// Values are generic json values, i.e. string or []interface{}
+type FieldDifference struct {
+	// Field is json name of the field, attributes are named without prefix, i.e. organisation_id or bank_id
+	Field string
+	// Desired is value of the field in desired account
+	Desired interface{}
+	// Existing is value of the field in existing account, nil when it isn't set there
+	Existing interface{}
+}
+
+// Compare returns fields set in desired account which have different value in existing account, sorted by field.
+// Fields not set in desired account are filled by api, so they aren't compared. When existing is nil,
+// all fields set in desired account are returned
+func Compare(desired *models.CreateAccountData, existing *models.AccountDataResponse) ([]FieldDifference, error) {
+	desiredFields, err := jsonFields(desired.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	desiredFields["organisation_id"] = desired.OrganisationID.String()
+
+	existingFields := make(map[string]interface{})
+	if existing != nil {
+		if existingFields, err = jsonFields(existing.Attributes); err != nil {
+			return nil, err
+		}
+		existingFields["organisation_id"] = existing.OrganisationID.String()
+	}
+
+	differences := make([]FieldDifference, 0)
+	for field, desiredValue := range desiredFields {
+		existingValue := existingFields[field]
+		if !reflect.DeepEqual(desiredValue, existingValue) {
+			differences = append(differences, FieldDifference{Field: field, Desired: desiredValue, Existing: existingValue})
+		}
+	}
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].Field < differences[j].Field
+	})
+	return differences, nil
+}
+
+// jsonFields returns json representation of value as map of its fields
+func jsonFields(value interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	content, err := json.Marshal(value)
+	if err == nil {
+		err = json.Unmarshal(content, &fields)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare accounts: %w", err)
+	}
+	if fields == nil {
+		// attributes which aren't set are marshalled as null
+		fields = make(map[string]interface{})
+	}
+	return fields, nil
+}
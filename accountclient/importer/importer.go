@@ -0,0 +1,320 @@
+// Package importer allows to create large number of accounts from CSV or JSON Lines files
+//
+// Importer reads rows from Source, validates them and creates accounts concurrently with bounded number of workers.
+// Result of each row is written to a CSV report. With checkpoint file ids of created accounts are stored as they are
+// created, so import which has crashed or has been interrupted can be run again with the same file and checkpoint,
+// and it continues without creating accounts twice. Accounts which already exist in api (409 status code) are treated
+// as created, which covers accounts created just before the crash, but not stored in checkpoint yet:
+//
+//	source, err := importer.NewSource(file, importer.FormatCSV)
+//	...
+//	summary, err := importer.New(client,
+//		importer.WithConcurrency(8),
+//		importer.WithCheckpoint("accounts.checkpoint"),
+//		importer.WithReport(reportFile)).Import(ctx, source)
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient"
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+const defaultConcurrency = 4
+
+// Status of imported row
+type Status string
+
+const (
+	// StatusCreated is set when account has been created
+	StatusCreated Status = "created"
+	// StatusExists is set when account with the same id already exists in api
+	StatusExists Status = "exists"
+	// StatusSkipped is set when account has been created by previous import stored in checkpoint
+	StatusSkipped Status = "skipped"
+	// StatusInvalid is set when row couldn't be parsed or validated. Such row is not sent to api
+	StatusInvalid Status = "invalid"
+	// StatusFailed is set when api returned error. Such row is imported again when import is resumed
+	StatusFailed Status = "failed"
+)
+
+// Result of single imported row
+type Result struct {
+	Line      int
+	AccountID uuid.UUID
+	Status    Status
+	Err       error
+}
+
+// Summary counts imported rows by their Status
+type Summary struct {
+	Total   int
+	Created int
+	Exists  int
+	Skipped int
+	Invalid int
+	Failed  int
+}
+
+func (s *Summary) add(status Status) {
+	s.Total++
+	switch status {
+	case StatusCreated:
+		s.Created++
+	case StatusExists:
+		s.Exists++
+	case StatusSkipped:
+		s.Skipped++
+	case StatusInvalid:
+		s.Invalid++
+	case StatusFailed:
+		s.Failed++
+	}
+}
+
+// Option modifies Importer
+type Option func(i *Importer)
+
+// WithConcurrency sets number of accounts created at the same time. By default, 4 accounts are created concurrently.
+// Concurrency shouldn't be higher than number of concurrent requests allowed by circuit breaker of the client
+func WithConcurrency(concurrency int) Option {
+	return func(i *Importer) {
+		if concurrency > 0 {
+			i.concurrency = concurrency
+		}
+	}
+}
+
+// WithCheckpoint stores ids of imported accounts in file under path and skips accounts which are already stored there
+func WithCheckpoint(path string) Option {
+	return func(i *Importer) {
+		i.checkpointPath = path
+	}
+}
+
+// WithReport writes result of each row to w in CSV format with line, account_id, status and error columns
+func WithReport(w io.Writer) Option {
+	return func(i *Importer) {
+		i.report = w
+	}
+}
+
+// WithProgress calls fn with current Summary after each imported row. Calls are not concurrent
+func WithProgress(fn func(summary Summary)) Option {
+	return func(i *Importer) {
+		i.progress = fn
+	}
+}
+
+// WithCallOptions sets options of each CreateAccount call
+func WithCallOptions(options ...accountclient.CallOption) Option {
+	return func(i *Importer) {
+		i.callOptions = options
+	}
+}
+
+// WithValidation replaces Validate, which checks rows before they are sent to api, with validate.
+// Row for which validate returns error is reported with StatusInvalid
+func WithValidation(validate func(request *models.CreateAccountRequest) error) Option {
+	return func(i *Importer) {
+		i.validate = validate
+	}
+}
+
+// WithVerifyExisting fetches accounts which already exist in api and compares them with imported ones. Account which
+// has different value of any field set in imported account is reported with StatusFailed and error matching
+// ErrAccountMismatch instead of StatusExists
+func WithVerifyExisting() Option {
+	return func(i *Importer) {
+		i.verify = true
+	}
+}
+
+// Importer creates accounts read from Source
+type Importer struct {
+	client         accountclient.AccountAPI
+	concurrency    int
+	checkpointPath string
+	report         io.Writer
+	progress       func(summary Summary)
+	callOptions    []accountclient.CallOption
+	validate       func(request *models.CreateAccountRequest) error
+	verify         bool
+}
+
+// New creates Importer which creates accounts with client
+func New(client accountclient.AccountAPI, options ...Option) *Importer {
+	i := &Importer{client: client, concurrency: defaultConcurrency, validate: Validate}
+	for _, option := range options {
+		option(i)
+	}
+	return i
+}
+
+// Import creates accounts from all rows of source. Rows which are invalid or couldn't be created don't stop import,
+// they are reported with StatusInvalid or StatusFailed. Error is returned when source can't be read, checkpoint
+// or report can't be written or ctx is done. Summary of rows imported until then is returned in all cases
+func (i *Importer) Import(ctx context.Context, source Source) (Summary, error) {
+	var cp *checkpoint
+	if i.checkpointPath != "" {
+		var err error
+		if cp, err = openCheckpoint(i.checkpointPath); err != nil {
+			return Summary{}, err
+		}
+		defer func() { _ = cp.close() }()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rows := make(chan Row)
+	results := make(chan Result)
+	var workers sync.WaitGroup
+	for n := 0; n < i.concurrency; n++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for row := range rows {
+				results <- i.importRow(ctx, cp, row)
+			}
+		}()
+	}
+
+	var (
+		summary   Summary
+		resultErr error
+		collected = make(chan struct{})
+	)
+	go func() {
+		defer close(collected)
+		resultErr = i.collect(results, cp, &summary)
+		if resultErr != nil {
+			cancel()
+		}
+	}()
+
+	readErr := i.read(ctx, source, rows)
+	close(rows)
+	workers.Wait()
+	close(results)
+	<-collected
+
+	switch {
+	case resultErr != nil:
+		return summary, resultErr
+	case readErr != nil:
+		return summary, readErr
+	default:
+		return summary, nil
+	}
+}
+
+// read sends rows of source until it's finished or ctx is done
+func (i *Importer) read(ctx context.Context, source Source, rows chan<- Row) error {
+	for {
+		row, err := source.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read import source: %w", err)
+		}
+		select {
+		case rows <- row:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// collect writes results to report and checkpoint and counts them in summary
+func (i *Importer) collect(results <-chan Result, cp *checkpoint, summary *Summary) error {
+	var report *csv.Writer
+	if i.report != nil {
+		report = csv.NewWriter(i.report)
+		_ = report.Write([]string{"line", "account_id", "status", "error"})
+	}
+
+	var err error
+	for result := range results {
+		// results received after failure are drained, but not counted as they can't be stored
+		if err != nil {
+			continue
+		}
+		if result.Status == StatusCreated || result.Status == StatusExists {
+			err = cp.markDone(result.AccountID)
+		}
+		if report != nil && err == nil {
+			err = writeResult(report, result)
+		}
+		summary.add(result.Status)
+		if i.progress != nil {
+			i.progress(*summary)
+		}
+	}
+	return err
+}
+
+func writeResult(report *csv.Writer, result Result) error {
+	accountID, errMsg := "", ""
+	if result.AccountID != uuid.Nil {
+		accountID = result.AccountID.String()
+	}
+	if result.Err != nil {
+		errMsg = result.Err.Error()
+	}
+	// write errors are returned by Error after flush
+	_ = report.Write([]string{strconv.Itoa(result.Line), accountID, string(result.Status), errMsg})
+	report.Flush()
+	if err := report.Error(); err != nil {
+		return fmt.Errorf("failed to write import report: %w", err)
+	}
+	return nil
+}
+
+func (i *Importer) importRow(ctx context.Context, cp *checkpoint, row Row) Result {
+	result := Result{Line: row.Line}
+	if row.Err != nil {
+		result.Status, result.Err = StatusInvalid, row.Err
+		return result
+	}
+	if row.Request.Data != nil {
+		result.AccountID = row.Request.Data.ID
+	}
+	if err := i.validate(row.Request); err != nil {
+		result.Status, result.Err = StatusInvalid, err
+		return result
+	}
+	if cp.isDone(result.AccountID) {
+		result.Status = StatusSkipped
+		return result
+	}
+
+	_, err := i.client.CreateAccount(ctx, row.Request, i.callOptions...)
+	var reqErr *accountclient.RequestError
+	switch {
+	case err == nil:
+		result.Status = StatusCreated
+	case errors.As(err, &reqErr) && reqErr.StatusCode == http.StatusConflict:
+		result.Status = StatusExists
+		if !i.verify {
+			break
+		}
+		if err = i.verifyExisting(ctx, row.Request); err != nil {
+			result.Status, result.Err = StatusFailed, err
+		}
+	default:
+		result.Status, result.Err = StatusFailed, err
+	}
+	return result
+}
@@ -0,0 +1,232 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient"
+	"github.com/arturskrzydlo/account-api-client/accountclient/accounttest"
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+type importerSuite struct {
+	suite.Suite
+
+	fakeAPI *accounttest.Server
+	client  *accountclient.Client
+}
+
+func TestImporter(t *testing.T) {
+	suite.Run(t, &importerSuite{})
+}
+
+func (s *importerSuite) SetupTest() {
+	s.fakeAPI = accounttest.NewServer()
+	client, err := accountclient.NewAccountClient(s.fakeAPI.BaseURL())
+	s.Require().NoError(err)
+	s.client = client
+}
+
+func (s *importerSuite) TearDownTest() {
+	s.fakeAPI.Close()
+	hystrix.Flush()
+}
+
+func csvRow(accountID, organisationID uuid.UUID, country, name string) string {
+	return fmt.Sprintf("%s,%s,%s,%s,400300,GBDSC\n", accountID, organisationID, country, name)
+}
+
+const csvHeader = "id,organisation_id,country,name,bank_id,bank_id_code\n"
+
+// reportStatuses returns statuses from report by line number
+func (s *importerSuite) reportStatuses(report string) map[string]string {
+	records, err := csv.NewReader(strings.NewReader(report)).ReadAll()
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"line", "account_id", "status", "error"}, records[0])
+	statuses := make(map[string]string)
+	for _, record := range records[1:] {
+		statuses[record[0]] = record[2]
+	}
+	return statuses
+}
+
+func (s *importerSuite) TestImport() {
+	s.Run("should create valid accounts from csv and report invalid rows", func() {
+		// given
+		organisationID := uuid.New()
+		accountID := uuid.New()
+		content := csvHeader +
+			csvRow(accountID, organisationID, "GB", "Samantha Holder;Sam Holder") +
+			csvRow(uuid.New(), organisationID, "GBR", "Samantha Holder") +
+			"not-an-id," + organisationID.String() + ",GB,Holder,,\n" +
+			csvRow(uuid.New(), organisationID, "FR", "Jean Dupont")
+		source, err := NewSource(strings.NewReader(content), FormatCSV)
+		s.Require().NoError(err)
+		var report bytes.Buffer
+		progressCalls := 0
+
+		// when
+		summary, err := New(s.client, WithReport(&report), WithProgress(func(Summary) { progressCalls++ })).
+			Import(context.Background(), source)
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(Summary{Total: 4, Created: 2, Invalid: 2}, summary)
+		s.Assert().Equal(4, progressCalls)
+		s.Assert().Equal(map[string]string{"2": "created", "3": "invalid", "4": "invalid", "5": "created"},
+			s.reportStatuses(report.String()))
+		s.Require().Len(s.fakeAPI.Accounts(), 2)
+		account, err := s.client.FetchAccount(context.Background(), accountID)
+		s.Require().NoError(err)
+		s.Assert().Equal([]string{"Samantha Holder", "Sam Holder"}, account.Data.Attributes.Name)
+	})
+
+	s.Run("should create accounts from json lines", func() {
+		// given
+		content := fmt.Sprintf(`{"data":{"id":"%s","organisation_id":"%s","attributes":{"country":"GB","name":["Holder"]}}}`+
+			"\n\n"+`{"data":`+"\n", uuid.New(), uuid.New())
+		source, err := NewSource(strings.NewReader(content), FormatJSONL)
+		s.Require().NoError(err)
+
+		// when
+		summary, err := New(s.client, WithConcurrency(2)).Import(context.Background(), source)
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(Summary{Total: 2, Created: 1, Invalid: 1}, summary)
+	})
+
+	s.Run("should fail when csv has unknown column", func() {
+		// when
+		_, err := NewSource(strings.NewReader("id,colour\n"), FormatCSV)
+
+		// then
+		s.Assert().ErrorContains(err, "colour")
+	})
+}
+
+func (s *importerSuite) TestResume() {
+	s.Run("should resume import without creating accounts twice", func() {
+		// given
+		organisationID := uuid.New()
+		accountIDs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+		content := csvHeader
+		for _, accountID := range accountIDs {
+			content += csvRow(accountID, organisationID, "GB", "Holder")
+		}
+		checkpointPath := filepath.Join(s.T().TempDir(), "import.checkpoint")
+		// the first account has been imported and stored in checkpoint, the second one was created,
+		// but import crashed before storing it
+		s.Require().NoError(os.WriteFile(checkpointPath, []byte(accountIDs[0].String()+"\n"+accountIDs[1].String()[:10]), 0o600))
+		for _, accountID := range accountIDs[:2] {
+			source, err := NewSource(strings.NewReader(csvHeader+csvRow(accountID, organisationID, "GB", "Holder")), FormatCSV)
+			s.Require().NoError(err)
+			_, err = New(s.client).Import(context.Background(), source)
+			s.Require().NoError(err)
+		}
+		source, err := NewSource(strings.NewReader(content), FormatCSV)
+		s.Require().NoError(err)
+
+		// when
+		summary, err := New(s.client, WithCheckpoint(checkpointPath)).Import(context.Background(), source)
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(Summary{Total: 3, Created: 1, Exists: 1, Skipped: 1}, summary)
+		s.Assert().Len(s.fakeAPI.Accounts(), 3)
+		checkpointContent, err := os.ReadFile(checkpointPath)
+		s.Require().NoError(err)
+		for _, accountID := range accountIDs {
+			s.Assert().Contains(string(checkpointContent), accountID.String()+"\n")
+		}
+	})
+
+	s.Run("should import failed rows again when import is resumed", func() {
+		// given
+		content := csvHeader + csvRow(uuid.New(), uuid.New(), "GB", "Holder") + csvRow(uuid.New(), uuid.New(), "GB", "Holder")
+		checkpointPath := filepath.Join(s.T().TempDir(), "import.checkpoint")
+		s.fakeAPI.InjectFault(accounttest.StatusFault(http.StatusServiceUnavailable, 1))
+		source, err := NewSource(strings.NewReader(content), FormatCSV)
+		s.Require().NoError(err)
+		importer := New(s.client, WithConcurrency(1), WithCheckpoint(checkpointPath),
+			WithCallOptions(accountclient.WithoutCircuitBreaker()))
+		firstSummary, err := importer.Import(context.Background(), source)
+		s.Require().NoError(err)
+		source, err = NewSource(strings.NewReader(content), FormatCSV)
+		s.Require().NoError(err)
+
+		// when
+		summary, err := importer.Import(context.Background(), source)
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(Summary{Total: 2, Created: 1, Failed: 1}, firstSummary)
+		s.Assert().Equal(Summary{Total: 2, Created: 1, Skipped: 1}, summary)
+	})
+}
+
+func (s *importerSuite) TestValidate() {
+	s.Run("should list all problems of account", func() {
+		// given
+		source, err := NewSource(strings.NewReader("id,country,name,bic,base_currency\n,gb,,NWBK,pounds\n"), FormatCSV)
+		s.Require().NoError(err)
+		row, err := source.Next()
+		s.Require().NoError(err)
+
+		// when
+		err = Validate(row.Request)
+
+		// then
+		var validationErr *ValidationError
+		s.Require().ErrorAs(err, &validationErr)
+		s.Assert().Len(validationErr.Problems, 6)
+	})
+}
+
+func (s *importerSuite) TestCompare() {
+	s.Run("should return fields set in desired account which differ from existing one", func() {
+		// given
+		organisationID, bankID, country := uuid.New(), "400300", "GB"
+		desired := &models.CreateAccountData{OrganisationID: organisationID, Attributes: &models.CreateAccountAttributes{
+			BankID: bankID, Country: &country, Name: []string{"Samantha Holder"},
+		}}
+		existing := &models.AccountDataResponse{OrganisationID: organisationID, Attributes: &models.AccountAttributesResponse{
+			BankID: "400301", Country: &country, Name: []string{"Samantha Holder"}, Bic: "NWBKGB22",
+		}}
+
+		// when
+		differences, err := Compare(desired, existing)
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal([]FieldDifference{{Field: "bank_id", Desired: bankID, Existing: "400301"}}, differences)
+	})
+
+	s.Run("should return all fields set in desired account when existing one is missing", func() {
+		// given
+		organisationID, country := uuid.New(), "GB"
+		desired := &models.CreateAccountData{OrganisationID: organisationID,
+			Attributes: &models.CreateAccountAttributes{Country: &country}}
+
+		// when
+		differences, err := Compare(desired, nil)
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal([]FieldDifference{
+			{Field: "country", Desired: country},
+			{Field: "organisation_id", Desired: organisationID.String()},
+		}, differences)
+	})
+}
@@ -0,0 +1,243 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+// Format of imported file
+type Format string
+
+const (
+	// FormatCSV is comma separated file with header row, see NewCSVSource
+	FormatCSV Format = "csv"
+	// FormatJSONL is JSON Lines file with single models.CreateAccountRequest in each line
+	FormatJSONL Format = "jsonl"
+)
+
+// Separator of multiple values in single CSV column, i.e. in name column
+const csvValueSeparator = ";"
+
+// FormatFromPath returns Format based on file extension: .csv for FormatCSV, .jsonl or .ndjson for FormatJSONL
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("unknown format of file %s, expected .csv, .jsonl or .ndjson extension", path)
+	}
+}
+
+// Row is single imported row. Err is set when row couldn't be parsed, in that case Request is nil
+type Row struct {
+	// Line is line number of the row in source file, starting from 1
+	Line    int
+	Request *models.CreateAccountRequest
+	Err     error
+}
+
+// Source reads rows of imported file. Next returns io.EOF when there are no more rows.
+// Rows which can't be parsed are returned with Err set, other errors stop import
+type Source interface {
+	Next() (Row, error)
+}
+
+// NewSource returns Source reading r in given Format
+func NewSource(r io.Reader, format Format) (Source, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVSource(r)
+	case FormatJSONL:
+		return NewJSONLSource(r), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// csvColumns maps CSV column name to function setting its value on request
+var csvColumns = map[string]func(data *models.CreateAccountData, value string) error{
+	"id": func(data *models.CreateAccountData, value string) (err error) {
+		data.ID, err = uuid.Parse(value)
+		return err
+	},
+	"organisation_id": func(data *models.CreateAccountData, value string) (err error) {
+		data.OrganisationID, err = uuid.Parse(value)
+		return err
+	},
+	"account_classification": func(data *models.CreateAccountData, value string) error {
+		data.Attributes.AccountClassification = &value
+		return nil
+	},
+	"account_matching_opt_out": func(data *models.CreateAccountData, value string) (err error) {
+		data.Attributes.AccountMatchingOptOut, err = parseBool(value)
+		return err
+	},
+	"account_number": func(data *models.CreateAccountData, value string) error {
+		data.Attributes.AccountNumber = value
+		return nil
+	},
+	"alternative_names": func(data *models.CreateAccountData, value string) error {
+		data.Attributes.AlternativeNames = splitValues(value)
+		return nil
+	},
+	"bank_id": func(data *models.CreateAccountData, value string) error {
+		data.Attributes.BankID = value
+		return nil
+	},
+	"bank_id_code": func(data *models.CreateAccountData, value string) error {
+		data.Attributes.BankIDCode = value
+		return nil
+	},
+	"base_currency": func(data *models.CreateAccountData, value string) error {
+		data.Attributes.BaseCurrency = value
+		return nil
+	},
+	"bic": func(data *models.CreateAccountData, value string) error {
+		data.Attributes.Bic = value
+		return nil
+	},
+	"country": func(data *models.CreateAccountData, value string) error {
+		data.Attributes.Country = &value
+		return nil
+	},
+	"iban": func(data *models.CreateAccountData, value string) error {
+		data.Attributes.Iban = value
+		return nil
+	},
+	"joint_account": func(data *models.CreateAccountData, value string) (err error) {
+		data.Attributes.JointAccount, err = parseBool(value)
+		return err
+	},
+	"name": func(data *models.CreateAccountData, value string) error {
+		data.Attributes.Name = splitValues(value)
+		return nil
+	},
+	"secondary_identification": func(data *models.CreateAccountData, value string) error {
+		data.Attributes.SecondaryIdentification = value
+		return nil
+	},
+}
+
+// CSVSource reads accounts from CSV file. The first row is header with column names equal to json names
+// of account fields, i.e. id, organisation_id, country, name, bank_id. Multiple names are separated with semicolon.
+// Empty values are treated as not set
+type CSVSource struct {
+	reader  *csv.Reader
+	columns []string
+}
+
+// NewCSVSource reads header of CSV file and returns CSVSource reading its rows
+func NewCSVSource(r io.Reader) (*CSVSource, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	columns := make([]string, 0, len(header))
+	for _, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if _, ok := csvColumns[column]; !ok {
+			return nil, fmt.Errorf("unknown csv column %q", column)
+		}
+		columns = append(columns, column)
+	}
+	return &CSVSource{reader: reader, columns: columns}, nil
+}
+
+// Next returns next row of CSV file
+func (s *CSVSource) Next() (Row, error) {
+	record, err := s.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return Row{Line: parseErr.StartLine, Err: err}, nil
+		}
+		return Row{}, err
+	}
+	line, _ := s.reader.FieldPos(0)
+	if len(record) != len(s.columns) {
+		return Row{Line: line, Err: fmt.Errorf("expected %d columns, got %d", len(s.columns), len(record))}, nil
+	}
+
+	data := &models.CreateAccountData{Attributes: &models.CreateAccountAttributes{}, Type: AccountType}
+	for i, value := range record {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		if err = csvColumns[s.columns[i]](data, value); err != nil {
+			return Row{Line: line, Err: fmt.Errorf("invalid %s: %w", s.columns[i], err)}, nil
+		}
+	}
+	return Row{Line: line, Request: &models.CreateAccountRequest{Data: data}}, nil
+}
+
+// JSONLSource reads accounts from JSON Lines file, each line contains models.CreateAccountRequest.
+// Empty lines are skipped
+type JSONLSource struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewJSONLSource returns JSONLSource reading r
+func NewJSONLSource(r io.Reader) *JSONLSource {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &JSONLSource{scanner: scanner}
+}
+
+// Next returns next row of JSON Lines file
+func (s *JSONLSource) Next() (Row, error) {
+	for s.scanner.Scan() {
+		s.line++
+		content := strings.TrimSpace(s.scanner.Text())
+		if content == "" {
+			continue
+		}
+		var request models.CreateAccountRequest
+		if err := json.Unmarshal([]byte(content), &request); err != nil {
+			return Row{Line: s.line, Err: fmt.Errorf("invalid json: %w", err)}, nil
+		}
+		if request.Data != nil && request.Data.Type == "" {
+			request.Data.Type = AccountType
+		}
+		return Row{Line: s.line, Request: &request}, nil
+	}
+	if err := s.scanner.Err(); err != nil {
+		return Row{}, fmt.Errorf("failed to read json lines: %w", err)
+	}
+	return Row{}, io.EOF
+}
+
+func splitValues(value string) []string {
+	values := make([]string, 0)
+	for _, v := range strings.Split(value, csvValueSeparator) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func parseBool(value string) (*bool, error) {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
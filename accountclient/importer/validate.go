@@ -0,0 +1,84 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+// AccountType is type of account resources of account api
+const AccountType = "accounts"
+
+const maxNames = 4
+
+var (
+	countryPattern  = regexp.MustCompile(`^[A-Z]{2}$`)
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+	bicPattern      = regexp.MustCompile(`^([A-Z]{6}[A-Z0-9]{2}|[A-Z]{6}[A-Z0-9]{5})$`)
+)
+
+// ValidationError lists all problems found in imported account
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid account: %s", strings.Join(e.Problems, ", "))
+}
+
+// Validate checks imported account before it's sent to api. Apart from fields required by api, account id is required,
+// as it's used to resume import without creating duplicated accounts. It returns *ValidationError when account is invalid
+func Validate(request *models.CreateAccountRequest) error {
+	if request == nil || request.Data == nil {
+		return &ValidationError{Problems: []string{"data is required"}}
+	}
+
+	data := request.Data
+	problems := make([]string, 0)
+	if data.ID == uuid.Nil {
+		problems = append(problems, "id is required")
+	}
+	if data.OrganisationID == uuid.Nil {
+		problems = append(problems, "organisation_id is required")
+	}
+	if data.Type != AccountType {
+		problems = append(problems, fmt.Sprintf("type should be %s", AccountType))
+	}
+
+	attributes := data.Attributes
+	if attributes == nil {
+		problems = append(problems, "attributes are required")
+		return &ValidationError{Problems: problems}
+	}
+	switch {
+	case attributes.Country == nil:
+		problems = append(problems, "country is required")
+	case !countryPattern.MatchString(*attributes.Country):
+		problems = append(problems, "country should be ISO 3166-1 alpha-2 code")
+	}
+	switch {
+	case len(attributes.Name) == 0:
+		problems = append(problems, "name is required")
+	case len(attributes.Name) > maxNames:
+		problems = append(problems, fmt.Sprintf("name should have at most %d items", maxNames))
+	}
+	if attributes.BaseCurrency != "" && !currencyPattern.MatchString(attributes.BaseCurrency) {
+		problems = append(problems, "base_currency should be ISO 4217 code")
+	}
+	if attributes.Bic != "" && !bicPattern.MatchString(attributes.Bic) {
+		problems = append(problems, "bic should have 8 or 11 characters")
+	}
+	if attributes.AccountClassification != nil &&
+		*attributes.AccountClassification != "Personal" && *attributes.AccountClassification != "Business" {
+		problems = append(problems, "account_classification should be Personal or Business")
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient"
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+// ErrAccountMismatch is matched by error of row whose account already exists in api with different data,
+// see WithVerifyExisting
+var ErrAccountMismatch = errors.New("existing account differs from imported one")
+
+// verifyExisting fetches account which already exists and checks that it has all values of imported account
+func (i *Importer) verifyExisting(ctx context.Context, request *models.CreateAccountRequest) error {
+	// cached account could be older than the one which exists
+	options := append(append([]accountclient.CallOption{}, i.callOptions...), accountclient.WithoutCache())
+	existing, err := i.client.FetchAccount(ctx, request.Data.ID, options...)
+	if err != nil {
+		return fmt.Errorf("failed to fetch existing account: %w", err)
+	}
+	if existing.Data == nil {
+		return errors.New("existing account has no data")
+	}
+	differences, err := Compare(request.Data, existing.Data)
+	if err != nil {
+		return err
+	}
+	if len(differences) == 0 {
+		return nil
+	}
+	fields := make([]string, 0, len(differences))
+	for _, difference := range differences {
+		fields = append(fields, difference.Field)
+	}
+	return fmt.Errorf("%w: %s", ErrAccountMismatch, strings.Join(fields, ", "))
+}
@@ -0,0 +1,90 @@
+package accountclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrClientClosed is returned by calls made after Client.Close has been called
+var ErrClientClosed = errors.New("client is closed")
+
+// lifecycleKey marks context of call registered by lifecycle
+type lifecycleKey struct{}
+
+// lifecycle tracks in-flight calls of Client, so it can be closed gracefully
+type lifecycle struct {
+	mu       sync.Mutex
+	closed   bool
+	inFlight int
+	drained  chan struct{}
+}
+
+// begin registers call, returned function has to be called when call finishes. Work started by call in progress
+// with returned ctx, i.e. requests of batch or retries, is registered also after Client has been closed,
+// so calls in progress can finish
+func (l *lifecycle) begin(ctx context.Context) (context.Context, func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	inCall := ctx.Value(lifecycleKey{}) == l
+	if l.closed && (!inCall || l.inFlight == 0) {
+		return ctx, nil, ErrClientClosed
+	}
+	l.inFlight++
+
+	var once sync.Once
+	end := func() { once.Do(l.end) }
+	if inCall {
+		return ctx, end, nil
+	}
+	return context.WithValue(ctx, lifecycleKey{}, l), end, nil
+}
+
+func (l *lifecycle) end() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	if l.closed && l.inFlight == 0 {
+		close(l.drained)
+	}
+}
+
+// close stops accepting calls and returns channel closed when all in-flight calls have finished
+func (l *lifecycle) close() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.closed {
+		l.closed = true
+		l.drained = make(chan struct{})
+		if l.inFlight == 0 {
+			close(l.drained)
+		}
+	}
+	return l.drained
+}
+
+// Close stops Client gracefully. New calls fail with ErrClientClosed, while calls already in progress, including their
+// retries and remaining items of batches, are allowed to finish until ctx is done. Afterwards probing of unhealthy
+// endpoints is stopped and idle connections are closed, also connections used to request OAuth2 tokens.
+// Client doesn't buffer logs or metrics, so there is nothing else to flush.
+// Error is returned when ctx is done before in-flight calls have finished. Close can be called many times
+func (c *Client) Close(ctx context.Context) error {
+	var err error
+	select {
+	case <-c.lifecycle.close():
+	case <-ctx.Done():
+		err = fmt.Errorf("failed to wait for in-flight requests: %w", ctx.Err())
+	}
+
+	if c.endpoints != nil {
+		c.endpoints.stopProbing()
+	}
+	for _, httpClient := range c.idleClients {
+		httpClient.CloseIdleConnections()
+	}
+	return err
+}
@@ -0,0 +1,186 @@
+package accountclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+// idleClosingTransport counts calls closing its idle connections
+type idleClosingTransport struct {
+	http.RoundTripper
+	closed int32
+}
+
+func (t *idleClosingTransport) CloseIdleConnections() {
+	atomic.AddInt32(&t.closed, 1)
+}
+
+func (s *accountAPIClientSuite) TestClose() {
+	s.Run("should reject calls after close", func() {
+		// given
+		release := make(chan struct{})
+		close(release)
+		server, requests, _ := blockingServer(release)
+		defer server.Close()
+		accountsClient, err := NewAccountClient(server.URL)
+		s.Require().NoError(err)
+
+		// when
+		s.Require().NoError(accountsClient.Close(context.Background()))
+		_, fetchErr := accountsClient.FetchAccount(context.Background(), uuid.New())
+		health, healthErr := accountsClient.Health(context.Background())
+
+		// then
+		s.Assert().ErrorIs(fetchErr, ErrClientClosed)
+		s.Assert().ErrorIs(healthErr, ErrClientClosed)
+		s.Assert().False(health.Ready())
+		s.Assert().Equal(int32(0), atomic.LoadInt32(requests))
+		s.Assert().NoError(accountsClient.Close(context.Background()))
+	})
+
+	s.Run("should close idle connections of transport wrapped by middlewares", func() {
+		// given
+		transport := &idleClosingTransport{RoundTripper: http.DefaultTransport}
+		passThrough := func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(next.RoundTrip)
+		}
+		accountsClient, err := NewAccountClient("http://some-api.com",
+			WithCustomHTTPClient(&http.Client{Transport: transport}), WithMiddleware(passThrough),
+			WithOAuth2ClientCredentials("http://some-api.com/token", "client", "secret"))
+		s.Require().NoError(err)
+
+		// when
+		err = accountsClient.Close(context.Background())
+
+		// then
+		// token client uses the same transport as requests to account api
+		s.Require().NoError(err)
+		s.Assert().Equal(int32(2), atomic.LoadInt32(&transport.closed))
+	})
+
+	s.Run("should reject fetches of cached accounts after close", func() {
+		// given
+		release := make(chan struct{})
+		close(release)
+		server, requests, _ := blockingServer(release)
+		defer server.Close()
+		accountsClient, err := NewAccountClient(server.URL, WithCache(NewLRUCache(10, time.Minute)))
+		s.Require().NoError(err)
+		accountID := uuid.New()
+		_, err = accountsClient.FetchAccount(context.Background(), accountID)
+		s.Require().NoError(err)
+
+		// when
+		s.Require().NoError(accountsClient.Close(context.Background()))
+		account, err := accountsClient.FetchAccount(context.Background(), accountID)
+
+		// then
+		s.Assert().ErrorIs(err, ErrClientClosed)
+		s.Assert().Nil(account)
+		s.Assert().Equal(int32(1), atomic.LoadInt32(requests))
+	})
+
+	s.Run("should let batch in progress finish before closing", func() {
+		// given
+		release := make(chan struct{})
+		server, requests, _ := blockingServer(release)
+		defer server.Close()
+		accountsClient, err := NewAccountClient(server.URL)
+		s.Require().NoError(err)
+		ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+		type fetchResult struct {
+			accounts []*models.AccountResponse
+			err      error
+		}
+		fetched := make(chan fetchResult, 1)
+		go func() {
+			accounts, _, err := accountsClient.FetchAccounts(context.Background(), ids, BatchOptions{Concurrency: 1})
+			fetched <- fetchResult{accounts: accounts, err: err}
+		}()
+		s.Require().Eventually(func() bool { return atomic.LoadInt32(requests) == 1 }, time.Second, time.Millisecond)
+
+		// when
+		closed := make(chan error, 1)
+		go func() {
+			closed <- accountsClient.Close(context.Background())
+		}()
+		s.Require().Eventually(func() bool {
+			_, err := accountsClient.FetchAccount(context.Background(), uuid.New())
+			return errors.Is(err, ErrClientClosed)
+		}, time.Second, time.Millisecond)
+		close(release)
+
+		// then
+		result := <-fetched
+		s.Assert().NoError(result.err)
+		s.Assert().Len(result.accounts, 3)
+		s.Assert().NotContains(result.accounts, (*models.AccountResponse)(nil))
+		s.Assert().NoError(<-closed)
+	})
+
+	s.Run("should wait for in-flight request before closing", func() {
+		// given
+		release := make(chan struct{})
+		server, requests, _ := blockingServer(release)
+		defer server.Close()
+		accountsClient, err := NewAccountClient(server.URL)
+		s.Require().NoError(err)
+		fetched := make(chan error, 1)
+		go func() {
+			_, err := accountsClient.FetchAccount(context.Background(), uuid.New(), WithoutCircuitBreaker())
+			fetched <- err
+		}()
+		s.Require().Eventually(func() bool { return atomic.LoadInt32(requests) == 1 }, time.Second, time.Millisecond)
+
+		// when
+		closed := make(chan error, 1)
+		go func() {
+			closed <- accountsClient.Close(context.Background())
+		}()
+
+		// then
+		s.Require().Eventually(func() bool {
+			_, err := accountsClient.FetchAccount(context.Background(), uuid.New())
+			return errors.Is(err, ErrClientClosed)
+		}, time.Second, time.Millisecond)
+		s.Assert().Empty(closed)
+
+		// when
+		close(release)
+
+		// then
+		s.Assert().NoError(<-fetched)
+		s.Assert().NoError(<-closed)
+	})
+
+	s.Run("should return error when in-flight request doesn't finish before ctx is done", func() {
+		// given
+		release := make(chan struct{})
+		defer close(release)
+		server, requests, _ := blockingServer(release)
+		defer server.Close()
+		accountsClient, err := NewAccountClient(server.URL)
+		s.Require().NoError(err)
+		fetchCtx, cancelFetch := context.WithCancel(context.Background())
+		defer cancelFetch()
+		go func() {
+			_, _ = accountsClient.FetchAccount(fetchCtx, uuid.New(), WithoutCircuitBreaker())
+		}()
+		s.Require().Eventually(func() bool { return atomic.LoadInt32(requests) == 1 }, time.Second, time.Millisecond)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// when
+		err = accountsClient.Close(ctx)
+
+		// then
+		s.Assert().ErrorIs(err, context.DeadlineExceeded)
+	})
+}
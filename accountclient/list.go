@@ -0,0 +1,86 @@
+package accountclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+// Names of account fields which can be used in ListOptions.Filters
+const (
+	FilterOrganisationID = "organisation_id"
+	FilterAccountNumber  = "account_number"
+	FilterBankID         = "bank_id"
+	FilterBankIDCode     = "bank_id_code"
+	FilterCountry        = "country"
+	FilterIban           = "iban"
+)
+
+// ListOptions selects page and filters of listed accounts
+type ListOptions struct {
+	// PageNumber is zero based number of page
+	PageNumber int
+	// PageSize is number of accounts on page. When it's 0 page size of api is used
+	PageSize int
+	// Filters maps field name (i.e. FilterCountry) to accepted values. Account matches filter
+	// when its field has any of the values, and it has to match all filters
+	Filters map[string][]string
+}
+
+func (o ListOptions) query() string {
+	query := make([]string, 0, len(o.Filters)+2)
+	if o.PageNumber > 0 {
+		query = append(query, "page[number]="+strconv.Itoa(o.PageNumber))
+	}
+	if o.PageSize > 0 {
+		query = append(query, "page[size]="+strconv.Itoa(o.PageSize))
+	}
+	for field, values := range o.Filters {
+		if len(values) == 0 {
+			continue
+		}
+		escaped := make([]string, 0, len(values))
+		for _, value := range values {
+			escaped = append(escaped, url.QueryEscape(value))
+		}
+		query = append(query, fmt.Sprintf("filter[%s]=%s", url.QueryEscape(field), strings.Join(escaped, ",")))
+	}
+	sort.Strings(query)
+	return strings.Join(query, "&")
+}
+
+// ListAccounts lists single page of accounts matching ListOptions filters. Next page exists when
+// Links.Next of models.AccountsResponse is not empty
+// If there will be 4xx or 500x error it can be in a form of RequestError, but currently not all 4xx errors are in the same format
+// In that case error msg will remain empty and only status code will be available
+// Other errors are returned as simple errors
+// CallOption can be used to modify behaviour of this single call
+func (c *Client) ListAccounts(ctx context.Context, listOptions ListOptions, options ...CallOption) (*models.AccountsResponse, error) {
+	ctx, end, err := c.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
+	listURL := fmt.Sprintf("%s/organisation/accounts", c.baseURL)
+	if query := listOptions.query(); query != "" {
+		listURL += "?" + query
+	}
+	request, err := http.NewRequest(http.MethodGet, listURL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create list accounts request: %w", err)
+	}
+
+	var accountsResponse models.AccountsResponse
+	err = c.sendRequest(ctx, request, &accountsResponse, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send list accounts request: %w", err)
+	}
+	return &accountsResponse, nil
+}
@@ -0,0 +1,59 @@
+package accountclient
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/accounttest"
+)
+
+func (s *accountAPIClientSuite) TestListAccounts() {
+	fakeAPI := accounttest.NewServer()
+	defer fakeAPI.Close()
+	accountsClient, err := NewAccountClient(fakeAPI.BaseURL())
+	s.Require().NoError(err)
+
+	organisationID := uuid.New()
+	createdIDs := make([]uuid.UUID, 0)
+	for i := 0; i < 3; i++ {
+		account := createAccountRequest()
+		account.Data.OrganisationID = organisationID
+		_, err = accountsClient.CreateAccount(context.Background(), account)
+		s.Require().NoError(err)
+		createdIDs = append(createdIDs, account.Data.ID)
+	}
+	_, err = accountsClient.CreateAccount(context.Background(), createAccountRequest())
+	s.Require().NoError(err)
+
+	s.Run("should list accounts matching filters page by page", func() {
+		// given
+		listOptions := ListOptions{
+			PageSize: 2,
+			Filters:  map[string][]string{FilterOrganisationID: {organisationID.String()}, FilterCountry: {"GB", "FR"}},
+		}
+
+		// when
+		firstPage, err := accountsClient.ListAccounts(context.Background(), listOptions)
+		s.Require().NoError(err)
+		listOptions.PageNumber++
+		secondPage, secondErr := accountsClient.ListAccounts(context.Background(), listOptions)
+
+		// then
+		s.Require().NoError(secondErr)
+		s.Require().Len(firstPage.Data, 2)
+		s.Require().Len(secondPage.Data, 1)
+		s.Assert().Equal(createdIDs, []uuid.UUID{firstPage.Data[0].ID, firstPage.Data[1].ID, secondPage.Data[0].ID})
+		s.Assert().NotEmpty(firstPage.Links.Next)
+		s.Assert().Empty(secondPage.Links.Next)
+	})
+
+	s.Run("should use api defaults when page options are not set", func() {
+		// when
+		accounts, err := accountsClient.ListAccounts(context.Background(), ListOptions{})
+
+		// then
+		s.Assert().NoError(err)
+		s.Assert().Len(accounts.Data, 4)
+	})
+}
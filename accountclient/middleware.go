@@ -0,0 +1,63 @@
+package accountclient
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Middleware wraps http.RoundTripper used by Client. It is applied on every request attempt, so it is also applied
+// on each retry. Middleware can be used to modify outgoing requests (i.e. authenticate them) or to observe responses
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is an adapter to allow the use of ordinary functions as http.RoundTripper
+type RoundTripperFunc func(request *http.Request) (*http.Response, error)
+
+// RoundTrip calls f(request)
+func (f RoundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// WithMiddleware is a predefined option to wrap transport of http.Client with Middleware.
+// Middlewares are applied in given order, so the first one sees the request as the first one.
+// http.Client passed with WithCustomHTTPClient is not modified, Client is using its copy
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(cfg *ClientConfig) {
+		cfg.Middlewares = append(cfg.Middlewares, middlewares...)
+	}
+}
+
+func applyMiddlewares(httpClient *http.Client, middlewares []Middleware) *http.Client {
+	if len(middlewares) == 0 {
+		return httpClient
+	}
+
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		transport = middlewares[i](transport)
+	}
+
+	wrappedClient := *httpClient
+	wrappedClient.Transport = transport
+	return &wrappedClient
+}
+
+// ReadBody returns body of request without modifying the request, as http.RoundTripper mustn't modify it, so it can
+// be used by Middleware. Body is read from a copy returned by GetBody, only body of request without GetBody is read,
+// as it can't be copied in other way. Such request has to be sent as a clone with the returned body.
+// Nil is returned for request without body
+func ReadBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil, nil
+	}
+	body := request.Body
+	if request.GetBody != nil {
+		var err error
+		if body, err = request.GetBody(); err != nil {
+			return nil, fmt.Errorf("failed to copy request body: %w", err)
+		}
+	}
+	return copyBody(body)
+}
@@ -75,3 +75,17 @@ type AccountAttributesResponse struct {
 	Status                  *string  `json:"status,omitempty"`
 	Switched                *bool    `json:"switched,omitempty"`
 }
+
+type AccountsResponse struct {
+	Data  []*AccountDataResponse `json:"data"`
+	Links *Links                 `json:"links,omitempty"`
+}
+
+// Links contains paths to other pages of listed resources. Next and Prev are empty on the last and the first page
+type Links struct {
+	First string `json:"first,omitempty"`
+	Last  string `json:"last,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Self  string `json:"self,omitempty"`
+}
@@ -0,0 +1,222 @@
+package accountclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokens are refreshed this long before their expiry to not send requests with token expiring on the way
+const tokenExpiryDelta = 30 * time.Second
+
+type oauth2Token struct {
+	accessToken string
+	// zero expiry means that token server hasn't provided expiration time and token is valid until api rejects it
+	expiry time.Time
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+type tokenFetch struct {
+	done  chan struct{}
+	token *oauth2Token
+	err   error
+}
+
+// clientCredentialsTokenSource fetches tokens with OAuth2 client credentials grant and caches them until shortly before
+// expiry. Concurrent callers share a single token request
+type clientCredentialsTokenSource struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	httpClient   *http.Client
+	now          func() time.Time
+
+	mu       sync.Mutex
+	token    *oauth2Token
+	inflight *tokenFetch
+}
+
+// WithOAuth2ClientCredentials is a predefined option which authenticates every request with bearer token obtained
+// from tokenURL with OAuth2 client credentials grant. Token is cached until shortly before it expires.
+// When api responds with 401 Unauthorized, token is refreshed and request is sent once again.
+// Tokens are requested with transport and timeout of configured http.Client, including TLS options,
+// but without middlewares
+func WithOAuth2ClientCredentials(tokenURL, clientID, clientSecret string, scopes ...string) ClientOption {
+	return func(cfg *ClientConfig) {
+		if _, err := url.ParseRequestURI(tokenURL); err != nil {
+			cfg.setErr(fmt.Errorf("invalid token url provided: %w", err))
+			return
+		}
+		tokenSource := &clientCredentialsTokenSource{
+			tokenURL:     tokenURL,
+			clientID:     clientID,
+			clientSecret: clientSecret,
+			scopes:       scopes,
+			now:          time.Now,
+		}
+		cfg.tokenSources = append(cfg.tokenSources, tokenSource)
+		cfg.Middlewares = append(cfg.Middlewares, bearerTokenMiddleware(tokenSource))
+	}
+}
+
+func bearerTokenMiddleware(tokenSource *clientCredentialsTokenSource) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			body, bodyErr := ReadBody(request)
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+
+			send := func(accessToken string) (*http.Response, error) {
+				authorizedRequest := request.Clone(request.Context())
+				if body != nil {
+					resetBody(authorizedRequest, body)
+				}
+				authorizedRequest.Header.Set("Authorization", "Bearer "+accessToken)
+				return next.RoundTrip(authorizedRequest)
+			}
+
+			token, err := tokenSource.Token(request.Context())
+			if err != nil {
+				return nil, err
+			}
+			response, err := send(token.accessToken)
+			if err != nil || response.StatusCode != http.StatusUnauthorized {
+				return response, err
+			}
+
+			// token might have been revoked or expired earlier than declared so refresh it and try once again
+			_, _ = io.Copy(io.Discard, response.Body)
+			_ = response.Body.Close()
+			tokenSource.invalidate(token)
+			token, err = tokenSource.Token(request.Context())
+			if err != nil {
+				return nil, err
+			}
+			return send(token.accessToken)
+		})
+	}
+}
+
+// tokenHTTPClient returns http.Client used to request tokens, it is copy of httpClient without middlewares
+func tokenHTTPClient(httpClient *http.Client) *http.Client {
+	tokenClient := *httpClient
+	if tokenClient.Timeout == 0 {
+		tokenClient.Timeout = defaultTimeout
+	}
+	return &tokenClient
+}
+
+// Token returns cached token if it's still valid, otherwise it fetches a new one
+func (ts *clientCredentialsTokenSource) Token(ctx context.Context) (*oauth2Token, error) {
+	ts.mu.Lock()
+	if ts.token != nil && ts.valid(ts.token) {
+		token := ts.token
+		ts.mu.Unlock()
+		return token, nil
+	}
+
+	fetch := ts.inflight
+	if fetch == nil {
+		fetch = &tokenFetch{done: make(chan struct{})}
+		ts.inflight = fetch
+		go ts.fetch(fetch)
+	}
+	ts.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to wait for oauth2 token: %w", ctx.Err())
+	case <-fetch.done:
+		return fetch.token, fetch.err
+	}
+}
+
+// invalidate drops cached token, but only if it's still the given one, so concurrent callers rejected with the same
+// token cause only one refresh
+func (ts *clientCredentialsTokenSource) invalidate(token *oauth2Token) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.token == token {
+		ts.token = nil
+	}
+}
+
+func (ts *clientCredentialsTokenSource) valid(token *oauth2Token) bool {
+	return token.expiry.IsZero() || ts.now().Add(tokenExpiryDelta).Before(token.expiry)
+}
+
+func (ts *clientCredentialsTokenSource) fetch(fetch *tokenFetch) {
+	// token is shared by all waiting callers, so it is not fetched with context of any of them
+	fetch.token, fetch.err = ts.requestToken(context.Background())
+
+	ts.mu.Lock()
+	if fetch.err == nil {
+		ts.token = fetch.token
+	}
+	ts.inflight = nil
+	ts.mu.Unlock()
+
+	close(fetch.done)
+}
+
+func (ts *clientCredentialsTokenSource) requestToken(ctx context.Context) (*oauth2Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(ts.scopes) > 0 {
+		form.Set("scope", strings.Join(ts.scopes, " "))
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, ts.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create oauth2 token request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.SetBasicAuth(url.QueryEscape(ts.clientID), url.QueryEscape(ts.clientSecret))
+
+	requestTime := ts.now()
+	response, err := ts.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request oauth2 token: %w", err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	resBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read oauth2 token response: %w", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to obtain oauth2 token: %w", newRequestErr(response.StatusCode, errors.New(string(resBody))))
+	}
+
+	var tokenRes tokenResponse
+	if err = json.Unmarshal(resBody, &tokenRes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshall oauth2 token response: %w", err)
+	}
+	if tokenRes.AccessToken == "" {
+		return nil, errors.New("failed to obtain oauth2 token: empty access token")
+	}
+	if tokenRes.TokenType != "" && !strings.EqualFold(tokenRes.TokenType, "bearer") {
+		return nil, fmt.Errorf("failed to obtain oauth2 token: unsupported token type %s", tokenRes.TokenType)
+	}
+
+	token := &oauth2Token{accessToken: tokenRes.AccessToken}
+	if tokenRes.ExpiresIn > 0 {
+		token.expiry = requestTime.Add(time.Duration(tokenRes.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
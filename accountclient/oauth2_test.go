@@ -0,0 +1,195 @@
+package accountclient
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+func newTestTokenServer(tokenCalls *int32, expiresIn int) *httptest.Server {
+	return httptest.NewServer(testTokenHandler(tokenCalls, expiresIn))
+}
+
+func testTokenHandler(tokenCalls *int32, expiresIn int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "client-id" || clientSecret != "secret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		call := atomic.AddInt32(tokenCalls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":%d}`, call, expiresIn)
+	})
+}
+
+func (s *accountAPIClientSuite) TestOAuth2ClientCredentials() {
+	s.Run("should authorize requests with cached token", func() {
+		// given
+		var tokenCalls int32
+		tokenServ := newTestTokenServer(&tokenCalls, 3600)
+		defer tokenServ.Close()
+
+		authorizationHeaders := make([]string, 0)
+		testServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorizationHeaders = append(authorizationHeaders, r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(`{"data":{}}`))
+		}))
+		defer testServ.Close()
+
+		accountsClient, err := NewAccountClient(testServ.URL,
+			WithOAuth2ClientCredentials(tokenServ.URL, "client-id", "secret", "accounts"))
+		s.Require().NoError(err)
+
+		// when
+		for i := 0; i < 3; i++ {
+			_, err = accountsClient.FetchAccount(context.Background(), uuid.New())
+			s.Require().NoError(err)
+		}
+
+		// then
+		s.Assert().Equal(int32(1), atomic.LoadInt32(&tokenCalls))
+		s.Assert().Equal([]string{"Bearer token-1", "Bearer token-1", "Bearer token-1"}, authorizationHeaders)
+	})
+
+	s.Run("should refresh token and retry request once when api responds with unauthorized", func() {
+		// given
+		var tokenCalls int32
+		tokenServ := newTestTokenServer(&tokenCalls, 3600)
+		defer tokenServ.Close()
+
+		authorizationHeaders := make([]string, 0)
+		testServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorizationHeaders = append(authorizationHeaders, r.Header.Get("Authorization"))
+			if r.Header.Get("Authorization") == "Bearer token-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"data":{}}`))
+		}))
+		defer testServ.Close()
+
+		accountsClient, err := NewAccountClient(testServ.URL,
+			WithOAuth2ClientCredentials(tokenServ.URL, "client-id", "secret"))
+		s.Require().NoError(err)
+
+		// when
+		_, err = accountsClient.CreateAccount(context.Background(), &models.CreateAccountRequest{
+			Data: &models.CreateAccountData{ID: uuid.New(), OrganisationID: uuid.New(), Type: "accounts"},
+		})
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(int32(2), atomic.LoadInt32(&tokenCalls))
+		s.Assert().Equal([]string{"Bearer token-1", "Bearer token-2"}, authorizationHeaders)
+	})
+
+	s.Run("should fetch token only once for concurrent callers", func() {
+		// given
+		var tokenCalls int32
+		tokenServ := newTestTokenServer(&tokenCalls, 3600)
+		defer tokenServ.Close()
+		tokenSource := &clientCredentialsTokenSource{
+			tokenURL:     tokenServ.URL,
+			clientID:     "client-id",
+			clientSecret: "secret",
+			httpClient:   &http.Client{Timeout: defaultTimeout},
+			now:          time.Now,
+		}
+
+		// when
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, err := tokenSource.Token(context.Background())
+				s.Assert().NoError(err)
+				s.Assert().Equal("token-1", token.accessToken)
+			}()
+		}
+		wg.Wait()
+
+		// then
+		s.Assert().Equal(int32(1), atomic.LoadInt32(&tokenCalls))
+	})
+
+	s.Run("should refresh token shortly before it expires", func() {
+		// given
+		var tokenCalls int32
+		tokenServ := newTestTokenServer(&tokenCalls, 60)
+		defer tokenServ.Close()
+		now := time.Now()
+		tokenSource := &clientCredentialsTokenSource{
+			tokenURL:     tokenServ.URL,
+			clientID:     "client-id",
+			clientSecret: "secret",
+			httpClient:   &http.Client{Timeout: defaultTimeout},
+			now:          func() time.Time { return now },
+		}
+		firstToken, err := tokenSource.Token(context.Background())
+		s.Require().NoError(err)
+
+		// when
+		now = now.Add(60*time.Second - tokenExpiryDelta)
+		secondToken, err := tokenSource.Token(context.Background())
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal("token-1", firstToken.accessToken)
+		s.Assert().Equal("token-2", secondToken.accessToken)
+	})
+
+	s.Run("should request token with TLS options of client", func() {
+		// given
+		var tokenCalls int32
+		tokenServ := httptest.NewTLSServer(testTokenHandler(&tokenCalls, 3600))
+		defer tokenServ.Close()
+		tokenServCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tokenServ.Certificate().Raw})
+
+		testServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"data":{}}`))
+		}))
+		defer testServ.Close()
+
+		accountsClient, err := NewAccountClient(testServ.URL, WithRootCAs(tokenServCA),
+			WithOAuth2ClientCredentials(tokenServ.URL, "client-id", "secret"))
+		s.Require().NoError(err)
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New())
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(int32(1), atomic.LoadInt32(&tokenCalls))
+	})
+
+	s.Run("should return error when token can't be obtained", func() {
+		// given
+		var tokenCalls int32
+		tokenServ := newTestTokenServer(&tokenCalls, 3600)
+		defer tokenServ.Close()
+
+		accountsClient, err := NewAccountClient("http://some-api.com",
+			WithOAuth2ClientCredentials(tokenServ.URL, "client-id", "invalid-secret"))
+		s.Require().NoError(err)
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New(), WithoutCircuitBreaker())
+
+		// then
+		var reqErr *RequestError
+		s.Assert().ErrorAs(err, &reqErr)
+		s.Assert().Equal(http.StatusUnauthorized, reqErr.StatusCode)
+	})
+}
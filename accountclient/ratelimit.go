@@ -0,0 +1,121 @@
+package accountclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// RateLimit limits number of requests sent by Client, see WithRateLimit
+type RateLimit struct {
+	// RequestsPerSecond is allowed average number of requests per second
+	RequestsPerSecond float64
+	// Burst is number of requests which can be sent at once after period without requests
+	Burst int
+	// Instances is number of clients sharing the limit, each of them gets equal part of it. 0 is treated as 1
+	Instances int
+}
+
+// WithRateLimit limits client to rps requests per second with bursts of up to burst requests. Limit is applied
+// before every request attempt, so retries are limited too. Request waiting for its turn stops waiting when its
+// context is done. Waiting for the first attempt isn't counted into call timeout and doesn't open circuit breaker,
+// waiting for retries is part of the call
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return WithDistributedRateLimit(rps, burst, 1)
+}
+
+// WithDistributedRateLimit is WithRateLimit for rps and burst shared by given number of instances, i.e. pods using
+// the same api quota. Each instance is limited to its equal part of rps and burst, burst is at least 1
+func WithDistributedRateLimit(rps float64, burst, instances int) ClientOption {
+	return func(cfg *ClientConfig) {
+		if rps <= 0 {
+			cfg.setErr(errors.New("rate limit must be greater than 0"))
+			return
+		}
+		if instances < 1 {
+			cfg.setErr(errors.New("number of instances sharing rate limit must be greater than 0"))
+			return
+		}
+		cfg.RateLimit = &RateLimit{RequestsPerSecond: rps, Burst: burst, Instances: instances}
+	}
+}
+
+// newRateLimiter creates tokenBucket for part of rateLimit used by single instance
+func newRateLimiter(rateLimit *RateLimit) *tokenBucket {
+	if rateLimit == nil {
+		return nil
+	}
+	instances := rateLimit.Instances
+	if instances < 1 {
+		instances = 1
+	}
+	return newTokenBucket(rateLimit.RequestsPerSecond/float64(instances), rateLimit.Burst/instances)
+}
+
+// tokenBucket allows rate events per second with bursts of up to burst events
+type tokenBucket struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket creates full tokenBucket. burst lower than 1 is treated as 1
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), now: time.Now}
+}
+
+// reserve takes token and returns how long caller has to wait before using it
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns token taken by reserve which hasn't been used
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// wait blocks until token is available or ctx is done
+func (b *tokenBucket) wait(ctx context.Context) error {
+	delay := b.reserve()
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
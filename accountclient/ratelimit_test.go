@@ -0,0 +1,116 @@
+package accountclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *accountAPIClientSuite) TestRateLimit() {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Query().Get("version") == "fail" {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"type":"accounts"}}`))
+	}))
+	defer server.Close()
+
+	s.Run("should limit number of requests per second", func() {
+		// given
+		accountsClient, err := NewAccountClient(server.URL, WithRateLimit(20, 1))
+		s.Require().NoError(err)
+		start := time.Now()
+
+		// when
+		for i := 0; i < 5; i++ {
+			_, err = accountsClient.FetchAccount(context.Background(), uuid.New(), WithoutCircuitBreaker())
+			s.Require().NoError(err)
+		}
+
+		// then
+		// the first request is sent immediately, next ones every 50ms
+		s.Assert().GreaterOrEqual(time.Since(start), 200*time.Millisecond)
+	})
+
+	s.Run("should limit retries", func() {
+		// given
+		atomic.StoreInt32(&requests, 0)
+		accountsClient, err := NewAccountClient(server.URL, WithRateLimit(20, 1), WithRetriesOnDefaultRetryPolicy(3))
+		s.Require().NoError(err)
+		failingRequest, err := http.NewRequest(http.MethodGet, server.URL+"?version=fail", http.NoBody)
+		s.Require().NoError(err)
+		start := time.Now()
+
+		// when
+		err = accountsClient.sendRequest(context.Background(), failingRequest, nil, []CallOption{WithoutCircuitBreaker()})
+
+		// then
+		s.Assert().Error(err)
+		s.Assert().Equal(int32(4), atomic.LoadInt32(&requests))
+		s.Assert().GreaterOrEqual(time.Since(start), 150*time.Millisecond)
+	})
+
+	s.Run("should not count waiting for rate limit into client timeout", func() {
+		// given
+		accountsClient, err := NewAccountClient(server.URL, WithRateLimit(10, 1),
+			WithCustomHTTPClient(&http.Client{Timeout: 50 * time.Millisecond}))
+		s.Require().NoError(err)
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New())
+		s.Require().NoError(err)
+		start := time.Now()
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New())
+
+		// then
+		// the second request waits around 100ms for its turn, which is longer than client timeout
+		s.Assert().NoError(err)
+		s.Assert().GreaterOrEqual(time.Since(start), 50*time.Millisecond)
+	})
+
+	s.Run("should stop waiting for rate limit when context is done", func() {
+		// given
+		accountsClient, err := NewAccountClient(server.URL, WithRateLimit(0.01, 1))
+		s.Require().NoError(err)
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New(), WithoutCircuitBreaker())
+		s.Require().NoError(err)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		// when
+		_, err = accountsClient.FetchAccount(ctx, uuid.New(), WithoutCircuitBreaker())
+
+		// then
+		s.Assert().ErrorIs(err, context.DeadlineExceeded)
+	})
+
+	s.Run("should divide distributed rate limit between instances", func() {
+		// when
+		limiter := newRateLimiter(&RateLimit{RequestsPerSecond: 40, Burst: 8, Instances: 4})
+
+		// then
+		s.Assert().Equal(10.0, limiter.rate)
+		s.Assert().Equal(2.0, limiter.burst)
+	})
+
+	s.Run("should not create client with invalid rate limit", func() {
+		testCases := map[string]ClientOption{
+			"zero rate":      WithRateLimit(0, 1),
+			"zero instances": WithDistributedRateLimit(10, 1, 0),
+		}
+		for name, option := range testCases {
+			// when
+			_, err := NewAccountClient(server.URL, option)
+
+			// then
+			s.Assert().Error(err, name)
+		}
+	})
+}
@@ -0,0 +1,358 @@
+// Package reconcile allows to manage accounts as code, similarly to terraform plan and apply
+//
+// Desired state is YAML or JSON document with accounts keyed by their id. Fields of accounts have the same names
+// as in account api:
+//
+//	ad27e265-9605-4b4b-a0e5-3003ea9cc4dc:
+//	  organisation_id: eb0bd6f5-c3f5-44b2-b677-acd23cdde73c
+//	  attributes:
+//	    country: GB
+//	    name: [Samantha Holder]
+//	    bank_id: "400300"
+//
+// NewPlan compares desired state with accounts which currently exist and returns Plan with accounts to create, update
+// and delete. By default only accounts from desired state are changed. Accounts missing in desired state are deleted
+// only from organisations which are pruned, see PlanOptions. Only attributes set in desired state are compared,
+// so values generated by api (i.e. iban) don't cause changes. Account api doesn't allow to modify accounts, so update
+// replaces account: it's deleted with version seen in plan and created again with the same id. Desired accounts are
+// validated before plan is made, so replaced account isn't deleted when it can't be created. When create fails
+// for other reason, Plan.Apply returns *IncompleteReplaceError.
+// Plan.Apply fails without making the change when account has been modified since plan was made
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient"
+	"github.com/arturskrzydlo/account-api-client/accountclient/importer"
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+const pageSize = 100
+
+// Action made on account to reach desired state
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// DesiredState maps account id to its desired data
+type DesiredState map[uuid.UUID]*models.CreateAccountData
+
+// Load reads DesiredState in YAML or JSON format from r
+func Load(r io.Reader) (DesiredState, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read desired state: %w", err)
+	}
+
+	// yaml is decoded to generic values and converted to json, so field names are taken from json tags of models
+	var document map[string]interface{}
+	if err = yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse desired state: %w", err)
+	}
+	jsonContent, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse desired state: %w", err)
+	}
+	var accounts map[string]*models.CreateAccountData
+	if err = json.Unmarshal(jsonContent, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to parse desired state: %w", err)
+	}
+
+	desired := make(DesiredState, len(accounts))
+	for key, data := range accounts {
+		accountID, parseErr := uuid.Parse(key)
+		if parseErr != nil {
+			return nil, fmt.Errorf("invalid account id %q in desired state: %w", key, parseErr)
+		}
+		if data == nil {
+			data = &models.CreateAccountData{}
+		}
+		if data.OrganisationID == uuid.Nil {
+			return nil, fmt.Errorf("account %s in desired state has no organisation_id", accountID)
+		}
+		if data.ID != uuid.Nil && data.ID != accountID {
+			return nil, fmt.Errorf("account %s in desired state has different id %s", accountID, data.ID)
+		}
+		data.ID = accountID
+		data.Type = importer.AccountType
+		data.Version = nil
+		desired[accountID] = data
+	}
+	return desired, nil
+}
+
+// AttributeDiff is change of single account field, values are json encoded. Empty value means that field is not set
+type AttributeDiff struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// Change of single account
+type Change struct {
+	Action    Action          `json:"action"`
+	AccountID uuid.UUID       `json:"account_id"`
+	Diffs     []AttributeDiff `json:"diffs,omitempty"`
+	// Desired is account created by create and update
+	Desired *models.CreateAccountData `json:"-"`
+	// Live is account deleted by update and delete. Its version is used to delete it
+	Live *models.AccountDataResponse `json:"-"`
+}
+
+// IncompleteReplaceError is returned by Plan.Apply when account replaced by update has been deleted, but it couldn't
+// be created again. Account doesn't exist until Desired is created, i.e. by apply of a new plan
+type IncompleteReplaceError struct {
+	AccountID uuid.UUID
+	Desired   *models.CreateAccountData
+	Err       error
+}
+
+func (e *IncompleteReplaceError) Error() string {
+	desired, err := json.Marshal(e.Desired)
+	if err != nil {
+		desired = []byte(err.Error())
+	}
+	return fmt.Sprintf("account %s has been deleted, but not created again: %s; desired account: %s",
+		e.AccountID, e.Err, desired)
+}
+
+func (e *IncompleteReplaceError) Unwrap() error {
+	return e.Err
+}
+
+// Plan lists changes needed to reach desired state, sorted by action and account id
+type Plan struct {
+	Changes []Change `json:"changes"`
+}
+
+// PlanOptions modify NewPlan
+type PlanOptions struct {
+	// OrganisationIDs are pruned: all their accounts which are not in desired state are deleted,
+	// so it allows to delete all accounts of organisation
+	OrganisationIDs []uuid.UUID
+	// Prune prunes also organisations of desired accounts
+	Prune bool
+}
+
+// NewPlan fetches accounts from desired state and accounts of pruned organisations with client and compares them
+// with desired state
+func NewPlan(ctx context.Context, client accountclient.AccountAPI, desired DesiredState, options PlanOptions) (*Plan, error) {
+	organisations := make(map[uuid.UUID]bool)
+	for _, organisationID := range options.OrganisationIDs {
+		organisations[organisationID] = true
+	}
+	if options.Prune {
+		for _, data := range desired {
+			organisations[data.OrganisationID] = true
+		}
+	}
+
+	live := make(map[uuid.UUID]*models.AccountDataResponse)
+	for organisationID := range organisations {
+		if err := listOrganisation(ctx, client, organisationID, live); err != nil {
+			return nil, err
+		}
+	}
+	// desired account may exist in organisation which isn't pruned
+	for accountID := range desired {
+		if _, ok := live[accountID]; ok {
+			continue
+		}
+		account, err := client.FetchAccount(ctx, accountID)
+		var reqErr *accountclient.RequestError
+		switch {
+		case err == nil:
+			live[accountID] = account.Data
+		case errors.As(err, &reqErr) && reqErr.StatusCode == http.StatusNotFound:
+		default:
+			return nil, fmt.Errorf("failed to fetch account %s: %w", accountID, err)
+		}
+	}
+
+	plan := &Plan{Changes: make([]Change, 0)}
+	for accountID, data := range desired {
+		// account replaced by update is deleted first, so it mustn't be rejected when it's created again
+		if err := importer.Validate(&models.CreateAccountRequest{Data: data}); err != nil {
+			return nil, fmt.Errorf("account %s in desired state is invalid: %w", accountID, err)
+		}
+		liveAccount, ok := live[accountID]
+		diffs, err := diff(liveAccount, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare account %s: %w", accountID, err)
+		}
+		if !ok {
+			plan.Changes = append(plan.Changes, Change{
+				Action: ActionCreate, AccountID: accountID, Desired: data, Diffs: diffs,
+			})
+			continue
+		}
+		if len(diffs) > 0 {
+			plan.Changes = append(plan.Changes, Change{
+				Action: ActionUpdate, AccountID: accountID, Desired: data, Live: liveAccount, Diffs: diffs,
+			})
+		}
+	}
+	for accountID, liveAccount := range live {
+		if _, ok := desired[accountID]; !ok {
+			plan.Changes = append(plan.Changes, Change{Action: ActionDelete, AccountID: accountID, Live: liveAccount})
+		}
+	}
+
+	actionOrder := map[Action]int{ActionDelete: 0, ActionUpdate: 1, ActionCreate: 2}
+	sort.Slice(plan.Changes, func(i, j int) bool {
+		if plan.Changes[i].Action != plan.Changes[j].Action {
+			return actionOrder[plan.Changes[i].Action] < actionOrder[plan.Changes[j].Action]
+		}
+		return plan.Changes[i].AccountID.String() < plan.Changes[j].AccountID.String()
+	})
+	return plan, nil
+}
+
+func listOrganisation(ctx context.Context, client accountclient.AccountAPI, organisationID uuid.UUID,
+	live map[uuid.UUID]*models.AccountDataResponse,
+) error {
+	listOptions := accountclient.ListOptions{
+		PageSize: pageSize,
+		Filters:  map[string][]string{accountclient.FilterOrganisationID: {organisationID.String()}},
+	}
+	for {
+		page, err := client.ListAccounts(ctx, listOptions)
+		if err != nil {
+			return fmt.Errorf("failed to list accounts of organisation %s: %w", organisationID, err)
+		}
+		for _, account := range page.Data {
+			live[account.ID] = account
+		}
+		if page.Links == nil || page.Links.Next == "" || len(page.Data) == 0 {
+			return nil
+		}
+		listOptions.PageNumber++
+	}
+}
+
+// diff compares fields set in desired account with live account, which can be nil when account doesn't exist
+func diff(live *models.AccountDataResponse, desired *models.CreateAccountData) ([]AttributeDiff, error) {
+	differences, err := importer.Compare(desired, live)
+	if err != nil {
+		return nil, err
+	}
+	diffs := make([]AttributeDiff, 0, len(differences))
+	for _, difference := range differences {
+		attributeDiff := AttributeDiff{Field: difference.Field, New: encode(difference.Desired)}
+		if difference.Existing != nil {
+			attributeDiff.Old = encode(difference.Existing)
+		}
+		diffs = append(diffs, attributeDiff)
+	}
+	return diffs, nil
+}
+
+func encode(value interface{}) string {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(content)
+}
+
+// IsEmpty returns true when live state already matches desired state
+func (p *Plan) IsEmpty() bool {
+	return len(p.Changes) == 0
+}
+
+// Count returns number of changes with given action
+func (p *Plan) Count(action Action) int {
+	count := 0
+	for _, change := range p.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Write writes human-readable plan to w
+func (p *Plan) Write(w io.Writer) error {
+	symbols := map[Action]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}
+	for _, change := range p.Changes {
+		header := fmt.Sprintf("%s %s account %s", symbols[change.Action], change.Action, change.AccountID)
+		if change.Action == ActionUpdate {
+			header += " (replace)"
+		}
+		if _, err := fmt.Fprintln(w, header); err != nil {
+			return err
+		}
+		for _, attributeDiff := range change.Diffs {
+			var line string
+			switch {
+			case attributeDiff.Old == "":
+				line = fmt.Sprintf("    %s: %s", attributeDiff.Field, attributeDiff.New)
+			default:
+				line = fmt.Sprintf("    %s: %s -> %s", attributeDiff.Field, attributeDiff.Old, attributeDiff.New)
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	if replaced := p.Count(ActionUpdate); replaced > 0 {
+		_, err := fmt.Fprintf(w, "Warning: %d accounts will be replaced. Replaced account is deleted and created again, "+
+			"it stays deleted when it can't be created\n", replaced)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "Plan: %d to create, %d to update, %d to delete\n",
+		p.Count(ActionCreate), p.Count(ActionUpdate), p.Count(ActionDelete))
+	return err
+}
+
+// Apply makes changes of plan with client in order of the plan. It stops at the first failed change and returns
+// number of changes which have been applied. Accounts are deleted with version seen in plan, so change of account
+// modified after plan has been made fails with conflict
+func (p *Plan) Apply(ctx context.Context, client accountclient.AccountAPI) (int, error) {
+	for i, change := range p.Changes {
+		if err := applyChange(ctx, client, change); err != nil {
+			return i, fmt.Errorf("failed to %s account %s: %w", change.Action, change.AccountID, err)
+		}
+	}
+	return len(p.Changes), nil
+}
+
+func applyChange(ctx context.Context, client accountclient.AccountAPI, change Change) error {
+	if change.Action == ActionUpdate || change.Action == ActionDelete {
+		if err := client.DeleteAccount(ctx, change.AccountID, liveVersion(change.Live)); err != nil {
+			return err
+		}
+	}
+	if change.Action == ActionUpdate || change.Action == ActionCreate {
+		_, err := client.CreateAccount(ctx, &models.CreateAccountRequest{Data: change.Desired})
+		if err != nil && change.Action == ActionUpdate {
+			return &IncompleteReplaceError{AccountID: change.AccountID, Desired: change.Desired, Err: err}
+		}
+		return err
+	}
+	return nil
+}
+
+func liveVersion(live *models.AccountDataResponse) *int64 {
+	if live == nil || live.Version == nil {
+		version := int64(0)
+		return &version
+	}
+	return live.Version
+}
@@ -0,0 +1,295 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient"
+	"github.com/arturskrzydlo/account-api-client/accountclient/accounttest"
+	"github.com/arturskrzydlo/account-api-client/accountclient/importer"
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+type reconcileSuite struct {
+	suite.Suite
+
+	fakeAPI *accounttest.Server
+	client  *accountclient.Client
+}
+
+func TestReconcile(t *testing.T) {
+	suite.Run(t, &reconcileSuite{})
+}
+
+func (s *reconcileSuite) SetupTest() {
+	s.fakeAPI = accounttest.NewServer()
+	client, err := accountclient.NewAccountClient(s.fakeAPI.BaseURL())
+	s.Require().NoError(err)
+	s.client = client
+}
+
+func (s *reconcileSuite) TearDownTest() {
+	s.fakeAPI.Close()
+	hystrix.Flush()
+}
+
+func (s *reconcileSuite) createAccount(accountID, organisationID uuid.UUID, bankID string) {
+	country := "GB"
+	_, err := s.client.CreateAccount(context.Background(), &models.CreateAccountRequest{Data: &models.CreateAccountData{
+		Attributes:     &models.CreateAccountAttributes{Country: &country, Name: []string{"Samantha Holder"}, BankID: bankID},
+		ID:             accountID,
+		OrganisationID: organisationID,
+		Type:           importer.AccountType,
+	}})
+	s.Require().NoError(err)
+}
+
+func desiredAccount(accountID, organisationID uuid.UUID, bankID string) string {
+	return fmt.Sprintf(`
+%s:
+  organisation_id: %s
+  attributes:
+    country: GB
+    name: [Samantha Holder]
+    bank_id: "%s"
+`, accountID, organisationID, bankID)
+}
+
+func (s *reconcileSuite) TestLoad() {
+	s.Run("should load desired state from YAML", func() {
+		// given
+		accountID, organisationID := uuid.New(), uuid.New()
+
+		// when
+		desired, err := Load(strings.NewReader(desiredAccount(accountID, organisationID, "400300")))
+
+		// then
+		s.Require().NoError(err)
+		s.Require().Contains(desired, accountID)
+		s.Assert().Equal(accountID, desired[accountID].ID)
+		s.Assert().Equal(organisationID, desired[accountID].OrganisationID)
+		s.Assert().Equal(importer.AccountType, desired[accountID].Type)
+		s.Assert().Equal("400300", desired[accountID].Attributes.BankID)
+		s.Assert().Equal([]string{"Samantha Holder"}, desired[accountID].Attributes.Name)
+	})
+
+	s.Run("should load desired state from JSON", func() {
+		// given
+		accountID, organisationID := uuid.New(), uuid.New()
+		content := fmt.Sprintf(`{"%s": {"organisation_id": "%s", "attributes": {"country": "FR"}}}`, accountID, organisationID)
+
+		// when
+		desired, err := Load(strings.NewReader(content))
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal("FR", *desired[accountID].Attributes.Country)
+	})
+
+	s.Run("should not load desired state with invalid account", func() {
+		testCases := map[string]string{
+			"invalid id":             "not-uuid:\n  organisation_id: " + uuid.NewString(),
+			"missing organisation":   uuid.NewString() + ":\n  attributes:\n    country: GB",
+			"different id in fields": fmt.Sprintf("%s:\n  id: %s\n  organisation_id: %s", uuid.New(), uuid.New(), uuid.New()),
+		}
+		for name, content := range testCases {
+			// when
+			_, err := Load(strings.NewReader(content))
+
+			// then
+			s.Assert().Error(err, name)
+		}
+	})
+}
+
+func (s *reconcileSuite) TestPlanAndApply() {
+	s.Run("should create, update and delete accounts to reach desired state", func() {
+		// given
+		organisationID := uuid.New()
+		unchangedID, updatedID, deletedID, createdID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		s.createAccount(unchangedID, organisationID, "400300")
+		s.createAccount(updatedID, organisationID, "400300")
+		s.createAccount(deletedID, organisationID, "400300")
+		otherOrganisationAccountID := uuid.New()
+		s.createAccount(otherOrganisationAccountID, uuid.New(), "400300")
+		desired, err := Load(strings.NewReader(desiredAccount(unchangedID, organisationID, "400300") +
+			desiredAccount(updatedID, organisationID, "400301") + desiredAccount(createdID, organisationID, "400302")))
+		s.Require().NoError(err)
+
+		// when
+		plan, err := NewPlan(context.Background(), s.client, desired, PlanOptions{Prune: true})
+
+		// then
+		s.Require().NoError(err)
+		s.Require().Len(plan.Changes, 3)
+		s.Assert().Equal(ActionDelete, plan.Changes[0].Action)
+		s.Assert().Equal(deletedID, plan.Changes[0].AccountID)
+		s.Assert().Equal(ActionUpdate, plan.Changes[1].Action)
+		s.Assert().Equal(updatedID, plan.Changes[1].AccountID)
+		s.Assert().Equal([]AttributeDiff{{Field: "bank_id", Old: `"400300"`, New: `"400301"`}}, plan.Changes[1].Diffs)
+		s.Assert().Equal(ActionCreate, plan.Changes[2].Action)
+		s.Assert().Equal(createdID, plan.Changes[2].AccountID)
+
+		var written bytes.Buffer
+		s.Require().NoError(plan.Write(&written))
+		s.Assert().Contains(written.String(), `bank_id: "400300" -> "400301"`)
+		s.Assert().Contains(written.String(), "Warning: 1 accounts will be replaced")
+		s.Assert().Contains(written.String(), "Plan: 1 to create, 1 to update, 1 to delete")
+
+		// when
+		applied, err := plan.Apply(context.Background(), s.client)
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(3, applied)
+		bankIDs := make(map[uuid.UUID]string)
+		for _, account := range s.fakeAPI.Accounts() {
+			bankIDs[account.ID] = account.Attributes.BankID
+		}
+		s.Assert().Equal(map[uuid.UUID]string{
+			unchangedID: "400300", updatedID: "400301", createdID: "400302", otherOrganisationAccountID: "400300",
+		}, bankIDs)
+
+		// when planned again
+		plan, err = NewPlan(context.Background(), s.client, desired, PlanOptions{Prune: true})
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().True(plan.IsEmpty())
+	})
+
+	s.Run("should not delete accounts missing in desired state without pruning", func() {
+		// given
+		s.fakeAPI.Reset()
+		organisationID := uuid.New()
+		desiredID, unrelatedID := uuid.New(), uuid.New()
+		s.createAccount(desiredID, organisationID, "400300")
+		s.createAccount(unrelatedID, organisationID, "400300")
+		desired, err := Load(strings.NewReader(desiredAccount(desiredID, organisationID, "400301")))
+		s.Require().NoError(err)
+
+		// when
+		plan, err := NewPlan(context.Background(), s.client, desired, PlanOptions{})
+		s.Require().NoError(err)
+		_, err = plan.Apply(context.Background(), s.client)
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(0, plan.Count(ActionDelete))
+		s.Assert().Equal(1, plan.Count(ActionUpdate))
+		accountIDs := make([]uuid.UUID, 0)
+		for _, account := range s.fakeAPI.Accounts() {
+			accountIDs = append(accountIDs, account.ID)
+		}
+		s.Assert().ElementsMatch([]uuid.UUID{desiredID, unrelatedID}, accountIDs)
+	})
+
+	s.Run("should delete all accounts of pruned organisation missing in desired state", func() {
+		// given
+		s.fakeAPI.Reset()
+		organisationID := uuid.New()
+		s.createAccount(uuid.New(), organisationID, "400300")
+		s.createAccount(uuid.New(), organisationID, "400300")
+
+		// when
+		plan, err := NewPlan(context.Background(), s.client, DesiredState{},
+			PlanOptions{OrganisationIDs: []uuid.UUID{organisationID}})
+		s.Require().NoError(err)
+		_, err = plan.Apply(context.Background(), s.client)
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal(2, plan.Count(ActionDelete))
+		s.Assert().Empty(s.fakeAPI.Accounts())
+	})
+
+	s.Run("should move account from organisation which isn't pruned", func() {
+		// given
+		s.fakeAPI.Reset()
+		accountID, organisationID := uuid.New(), uuid.New()
+		s.createAccount(accountID, uuid.New(), "400300")
+		desired, err := Load(strings.NewReader(desiredAccount(accountID, organisationID, "400300")))
+		s.Require().NoError(err)
+
+		// when
+		plan, err := NewPlan(context.Background(), s.client, desired, PlanOptions{})
+
+		// then
+		s.Require().NoError(err)
+		s.Require().Len(plan.Changes, 1)
+		s.Assert().Equal(ActionUpdate, plan.Changes[0].Action)
+		s.Assert().Equal("organisation_id", plan.Changes[0].Diffs[0].Field)
+	})
+
+	s.Run("should return desired account when replaced account can't be created again", func() {
+		// given
+		s.fakeAPI.Reset()
+		accountID, organisationID := uuid.New(), uuid.New()
+		s.createAccount(accountID, organisationID, "400300")
+		desired, err := Load(strings.NewReader(desiredAccount(accountID, organisationID, "400301")))
+		s.Require().NoError(err)
+		plan, err := NewPlan(context.Background(), s.client, desired, PlanOptions{})
+		s.Require().NoError(err)
+		s.fakeAPI.InjectFault(accounttest.StatusFault(http.StatusServiceUnavailable, 1).WithMethod(http.MethodPost))
+
+		// when
+		_, err = plan.Apply(context.Background(), s.client)
+
+		// then
+		var replaceErr *IncompleteReplaceError
+		s.Require().ErrorAs(err, &replaceErr)
+		s.Assert().Equal(accountID, replaceErr.AccountID)
+		s.Assert().Equal(desired[accountID], replaceErr.Desired)
+		s.Assert().Contains(err.Error(), `"bank_id":"400301"`)
+		s.Assert().Empty(s.fakeAPI.Accounts())
+	})
+
+	s.Run("should not plan desired account which can't be created", func() {
+		// given
+		s.fakeAPI.Reset()
+		accountID, organisationID := uuid.New(), uuid.New()
+		s.createAccount(accountID, organisationID, "400300")
+		desired, err := Load(strings.NewReader(fmt.Sprintf("%s:\n  organisation_id: %s\n  attributes:\n    country: GB\n",
+			accountID, organisationID)))
+		s.Require().NoError(err)
+
+		// when
+		_, err = NewPlan(context.Background(), s.client, desired, PlanOptions{})
+
+		// then
+		var validationErr *importer.ValidationError
+		s.Assert().ErrorAs(err, &validationErr)
+		s.Assert().Len(s.fakeAPI.Accounts(), 1)
+	})
+
+	s.Run("should not recreate account deleted since plan was made", func() {
+		// given
+		s.fakeAPI.Reset()
+		accountID, organisationID := uuid.New(), uuid.New()
+		s.createAccount(accountID, organisationID, "400300")
+		desired, err := Load(strings.NewReader(desiredAccount(accountID, organisationID, "400301")))
+		s.Require().NoError(err)
+		plan, err := NewPlan(context.Background(), s.client, desired, PlanOptions{})
+		s.Require().NoError(err)
+		s.Require().NoError(s.client.DeleteAccount(context.Background(), accountID, plan.Changes[0].Live.Version))
+
+		// when
+		applied, err := plan.Apply(context.Background(), s.client)
+
+		// then
+		var reqErr *accountclient.RequestError
+		s.Require().True(errors.As(err, &reqErr))
+		s.Assert().Equal(http.StatusNotFound, reqErr.StatusCode)
+		s.Assert().Equal(0, applied)
+		s.Assert().Empty(s.fakeAPI.Accounts())
+	})
+}
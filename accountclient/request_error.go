@@ -13,6 +13,8 @@ type errResponseBody struct {
 type RequestError struct {
 	StatusCode int
 	ErrMsg     string
+	// RequestID is X-Request-ID sent with failed request. It can be used to correlate error with api server logs
+	RequestID string
 }
 
 func newRequestErr(statusCode int, err error) *RequestError {
@@ -23,18 +25,24 @@ func newRequestErr(statusCode int, err error) *RequestError {
 }
 
 func (r *RequestError) Error() string {
+	if r.RequestID != "" {
+		return fmt.Sprintf("status %d: error: %v: request id: %s", r.StatusCode, r.ErrMsg, r.RequestID)
+	}
 	return fmt.Sprintf("status %d: error: %v", r.StatusCode, r.ErrMsg)
 }
 
-func (c *Client) reqErrFromResponse(responseBody []byte, statusCode int) error {
+func (c *Client) reqErrFromResponse(responseBody []byte, statusCode int, requestID string) error {
 	var errResBody errResponseBody
-	err := json.Unmarshal(responseBody, &errResBody)
-	if err != nil {
-		// in case when error message is not in defined format try to get whole response as a string
-		// I've noticed that there are differences in api and returned error message format i.e. between 400 and 403.
-		// Also, when there is no response body, like for 404 we should be able to still return
-		// requestErr but with empty error message
-		return newRequestErr(statusCode, errors.New(string(responseBody)))
+	errMsg := string(responseBody)
+	// in case when error message is not in defined format try to get whole response as a string
+	// I've noticed that there are differences in api and returned error message format i.e. between 400 and 403.
+	// Also, when there is no response body, like for 404 we should be able to still return
+	// requestErr but with empty error message
+	if err := json.Unmarshal(responseBody, &errResBody); err == nil {
+		errMsg = errResBody.ErrorMessage
 	}
-	return newRequestErr(statusCode, errors.New(errResBody.ErrorMessage))
+
+	reqErr := newRequestErr(statusCode, errors.New(errMsg))
+	reqErr.RequestID = requestID
+	return reqErr
 }
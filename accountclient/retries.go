@@ -2,6 +2,7 @@ package accountclient
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -43,7 +44,12 @@ func (r retrier) retry(request *http.Request, fn func(request *http.Request) (*h
 			return res, err
 		}
 
-		time.Sleep(r.backoff.Delay(retriesCount))
+		if waitErr := wait(request.Context(), r.backoff.Delay(retriesCount)); waitErr != nil {
+			if res != nil {
+				_ = res.Body.Close()
+			}
+			return nil, fmt.Errorf("failed to wait for retry: %w", waitErr)
+		}
 		resetBody(request, originalBody)
 		res, err = fn(request)
 		retriesCount++
@@ -51,6 +57,21 @@ func (r retrier) retry(request *http.Request, fn func(request *http.Request) (*h
 	return res, err
 }
 
+// wait blocks for delay or until ctx is done
+func wait(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // RetryPolicy allows to create custom policy for errors on which library will try to retry request
 type RetryPolicy interface {
 	// ShouldRetry based on error and http.Response decides if request should be retried
@@ -128,6 +149,9 @@ func (mrp DefaultRetryPolicy) ShouldRetry(err error, response *http.Response) bo
 
 func resetBody(request *http.Request, originalBody []byte) {
 	request.Body = io.NopCloser(bytes.NewBuffer(originalBody))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(originalBody)), nil
+	}
 }
 
 func copyBody(src io.ReadCloser) ([]byte, error) {
@@ -0,0 +1,130 @@
+package accountclient
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	signatureAlgorithm = "rsa-sha256"
+	signedHeaders      = "(request-target) host date digest"
+)
+
+// requestSigner signs requests with HTTP message signature (draft-cavage-http-signatures) in a format
+// required by Form3 API. Signature is sent in Authorization header with Signature scheme
+type requestSigner struct {
+	keyID      string
+	privateKey *rsa.PrivateKey
+	now        func() time.Time
+}
+
+// WithRequestSigning is a predefined option which signs every request with key identified by keyID.
+// privateKeyPEM must contain RSA private key in PKCS #1 or PKCS #8 PEM format.
+// If private key can't be parsed NewAccountClient returns error
+func WithRequestSigning(keyID string, privateKeyPEM []byte) ClientOption {
+	return func(cfg *ClientConfig) {
+		middleware, err := NewRequestSigningMiddleware(keyID, privateKeyPEM)
+		if err != nil {
+			cfg.setErr(err)
+			return
+		}
+		cfg.Middlewares = append(cfg.Middlewares, middleware)
+	}
+}
+
+// NewRequestSigningMiddleware creates Middleware which signs every request with key identified by keyID.
+// Each request gets Date (if not set), Digest and Authorization headers.
+// Signature covers headers "(request-target) host date digest"
+func NewRequestSigningMiddleware(keyID string, privateKeyPEM []byte) (Middleware, error) {
+	privateKey, err := parseRSAPrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	signer := requestSigner{keyID: keyID, privateKey: privateKey, now: time.Now}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			signedRequest, signErr := signer.sign(request)
+			if signErr != nil {
+				return nil, signErr
+			}
+			return next.RoundTrip(signedRequest)
+		})
+	}, nil
+}
+
+// sign returns signed copy of the request. Original request is not modified, see ReadBody
+func (s requestSigner) sign(request *http.Request) (*http.Request, error) {
+	body, err := ReadBody(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body to sign: %w", err)
+	}
+	signedRequest := request.Clone(request.Context())
+	if body != nil {
+		resetBody(signedRequest, body)
+	}
+
+	if signedRequest.Header.Get("Date") == "" {
+		signedRequest.Header.Set("Date", s.now().UTC().Format(http.TimeFormat))
+	}
+	digest := sha256.Sum256(body)
+	signedRequest.Header.Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(digest[:]))
+
+	hashed := sha256.Sum256([]byte(signingString(signedRequest)))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	signedRequest.Header.Set("Authorization", fmt.Sprintf(`Signature keyId="%s",algorithm="%s",headers="%s",signature="%s"`,
+		s.keyID, signatureAlgorithm, signedHeaders, base64.StdEncoding.EncodeToString(signature)))
+
+	return signedRequest, nil
+}
+
+func signingString(request *http.Request) string {
+	host := request.Host
+	if host == "" {
+		host = request.URL.Host
+	}
+
+	lines := []string{
+		fmt.Sprintf("(request-target): %s %s", strings.ToLower(request.Method), request.URL.RequestURI()),
+		fmt.Sprintf("host: %s", host),
+		fmt.Sprintf("date: %s", request.Header.Get("Date")),
+		fmt.Sprintf("digest: %s", request.Header.Get("Digest")),
+	}
+	return strings.Join(lines, "\n")
+}
+
+func parseRSAPrivateKey(privateKeyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(bytes.TrimSpace(privateKeyPEM))
+	if block == nil {
+		return nil, errors.New("failed to decode private key: no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("failed to parse private key: key is not RSA private key")
+	}
+	return rsaKey, nil
+}
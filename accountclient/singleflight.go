@@ -0,0 +1,96 @@
+package accountclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+// fetchGroup coalesces concurrent fetches of the same account, so only one request is sent to api
+// and counted by circuit breaker. Shared request runs with context detached from callers, which is cancelled
+// only when all waiting callers have given up. Every caller stops waiting when its own context is done
+type fetchGroup struct {
+	mu    sync.Mutex
+	calls map[uuid.UUID]*fetchCall
+}
+
+type fetchCall struct {
+	done    chan struct{}
+	account *models.AccountResponse
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+type fetchFunc func(ctx context.Context) (*models.AccountResponse, error)
+
+// do returns result of fetch already in progress for accountID or starts new one
+func (g *fetchGroup) do(ctx context.Context, accountID uuid.UUID, fetch fetchFunc) (*models.AccountResponse, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[uuid.UUID]*fetchCall)
+	}
+	call, ok := g.calls[accountID]
+	if !ok {
+		callCtx, cancel := context.WithCancel(detachedContext{parent: ctx})
+		call = &fetchCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[accountID] = call
+		go g.run(callCtx, accountID, call, fetch)
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return nil, call.err
+		}
+		// every caller gets its own copy, so callers can't modify account returned to others
+		return copyAccount(call.account)
+	case <-ctx.Done():
+		g.leave(accountID, call)
+		return nil, ctx.Err()
+	}
+}
+
+func (g *fetchGroup) run(ctx context.Context, accountID uuid.UUID, call *fetchCall, fetch fetchFunc) {
+	defer call.cancel()
+	call.account, call.err = fetch(ctx)
+
+	g.mu.Lock()
+	if g.calls[accountID] == call {
+		delete(g.calls, accountID)
+	}
+	g.mu.Unlock()
+	close(call.done)
+}
+
+// leave removes caller which stopped waiting. Fetch is cancelled when nobody waits for it,
+// callers coming later start a new one
+func (g *fetchGroup) leave(accountID uuid.UUID, call *fetchCall) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	if g.calls[accountID] == call {
+		delete(g.calls, accountID)
+	}
+	call.cancel()
+}
+
+// detachedContext keeps values of parent context, i.e. used by middlewares, but is never cancelled by it
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (deadline time.Time, ok bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}                   { return nil }
+func (detachedContext) Err() error                              { return nil }
+func (d detachedContext) Value(key interface{}) interface{}     { return d.parent.Value(key) }
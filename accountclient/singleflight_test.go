@@ -0,0 +1,154 @@
+package accountclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/google/uuid"
+)
+
+// blockingServer returns account for every fetch after release is closed and counts received requests.
+// cancelled is closed when request is cancelled by client
+func blockingServer(release <-chan struct{}) (server *httptest.Server, requests *int32, cancelled chan struct{}) {
+	requests = new(int32)
+	cancelled = make(chan struct{}, 10)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		select {
+		case <-release:
+			w.Header().Set("Content-Type", jsonType)
+			_, _ = w.Write([]byte(`{"data":{"id":"` + r.URL.Path[len(r.URL.Path)-36:] + `","type":"accounts"}}`))
+		case <-r.Context().Done():
+			cancelled <- struct{}{}
+		}
+	}))
+	return server, requests, cancelled
+}
+
+func (s *accountAPIClientSuite) waitForWaiters(client *Client, accountID uuid.UUID, waiters int) {
+	s.Require().Eventually(func() bool {
+		client.fetches.mu.Lock()
+		defer client.fetches.mu.Unlock()
+		call, ok := client.fetches.calls[accountID]
+		return ok && call.waiters == waiters
+	}, time.Second, time.Millisecond)
+}
+
+func (s *accountAPIClientSuite) TestFetchAccountCoalescing() {
+	defer hystrix.Flush()
+
+	s.Run("should send one request for concurrent fetches of the same account", func() {
+		// given
+		release := make(chan struct{})
+		server, requests, _ := blockingServer(release)
+		defer server.Close()
+		accountsClient, err := NewAccountClient(server.URL)
+		s.Require().NoError(err)
+		accountID := uuid.New()
+		callers := 5
+
+		// when
+		var wg sync.WaitGroup
+		errs := make(chan error, callers)
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				account, fetchErr := accountsClient.FetchAccount(context.Background(), accountID)
+				if fetchErr == nil && account.Data.ID != accountID {
+					fetchErr = errors.New("unexpected account fetched")
+				}
+				errs <- fetchErr
+			}()
+		}
+		s.waitForWaiters(accountsClient, accountID, callers)
+		close(release)
+		wg.Wait()
+		close(errs)
+
+		// then
+		for fetchErr := range errs {
+			s.Assert().NoError(fetchErr)
+		}
+		s.Assert().Equal(int32(1), atomic.LoadInt32(requests))
+	})
+
+	s.Run("should return when caller's context is cancelled and keep fetching for others", func() {
+		// given
+		release := make(chan struct{})
+		server, requests, _ := blockingServer(release)
+		defer server.Close()
+		accountsClient, err := NewAccountClient(server.URL)
+		s.Require().NoError(err)
+		accountID := uuid.New()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancelledErr := make(chan error, 1)
+		otherErr := make(chan error, 1)
+		go func() {
+			_, fetchErr := accountsClient.FetchAccount(ctx, accountID)
+			cancelledErr <- fetchErr
+		}()
+		go func() {
+			_, fetchErr := accountsClient.FetchAccount(context.Background(), accountID)
+			otherErr <- fetchErr
+		}()
+		s.waitForWaiters(accountsClient, accountID, 2)
+
+		// when
+		cancel()
+
+		// then
+		s.Assert().ErrorIs(<-cancelledErr, context.Canceled)
+		close(release)
+		s.Assert().NoError(<-otherErr)
+		s.Assert().Equal(int32(1), atomic.LoadInt32(requests))
+	})
+
+	s.Run("should cancel request when all callers have given up", func() {
+		// given
+		server, _, cancelled := blockingServer(make(chan struct{}))
+		defer server.Close()
+		accountsClient, err := NewAccountClient(server.URL)
+		s.Require().NoError(err)
+		accountID := uuid.New()
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		// when
+		_, err = accountsClient.FetchAccount(ctx, accountID)
+
+		// then
+		s.Assert().ErrorIs(err, context.DeadlineExceeded)
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			s.Fail("request hasn't been cancelled")
+		}
+	})
+
+	s.Run("should send separate requests for fetches with call options", func() {
+		// given
+		release := make(chan struct{})
+		close(release)
+		server, requests, _ := blockingServer(release)
+		defer server.Close()
+		accountsClient, err := NewAccountClient(server.URL)
+		s.Require().NoError(err)
+		accountID := uuid.New()
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), accountID, WithoutCircuitBreaker())
+		s.Require().NoError(err)
+		_, err = accountsClient.FetchAccount(context.Background(), accountID, WithoutCircuitBreaker())
+		s.Require().NoError(err)
+
+		// then
+		s.Assert().Equal(int32(2), atomic.LoadInt32(requests))
+	})
+}
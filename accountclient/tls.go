@@ -0,0 +1,177 @@
+package accountclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// WithTLSConfig is a predefined option to set tls.Config used by Client transport. Given config is cloned.
+// Certificates and root CAs set by TLS options passed before it are kept, and NewAccountClient fails when given config
+// sets them too, as one of them would be ignored.
+// Transport of http.Client is cloned, so http.Client timeout (and circuit breaker timeout) is not affected
+func WithTLSConfig(tlsConfig *tls.Config) ClientOption {
+	return func(cfg *ClientConfig) {
+		merged := tlsConfig.Clone()
+		if previous := cfg.TLSConfig; previous != nil {
+			if err := mergeTLSConfig(merged, previous); err != nil {
+				cfg.setErr(err)
+				return
+			}
+		}
+		cfg.TLSConfig = merged
+	}
+}
+
+// mergeTLSConfig copies certificates and root CAs set by previous TLS options to tlsConfig
+func mergeTLSConfig(tlsConfig, previous *tls.Config) error {
+	if len(previous.Certificates) > 0 || previous.GetClientCertificate != nil {
+		if len(tlsConfig.Certificates) > 0 || tlsConfig.GetClientCertificate != nil {
+			return errors.New("TLS config overrides client certificate set by previous option")
+		}
+		tlsConfig.Certificates = previous.Certificates
+		tlsConfig.GetClientCertificate = previous.GetClientCertificate
+	}
+	if previous.RootCAs != nil {
+		if tlsConfig.RootCAs != nil {
+			return errors.New("TLS config overrides root CAs set by previous option")
+		}
+		tlsConfig.RootCAs = previous.RootCAs
+	}
+	return nil
+}
+
+// WithClientCertificate is a predefined option to authenticate Client with certificate in mutual TLS.
+// certPEM and keyPEM must contain PEM encoded certificate (chain) and its private key
+func WithClientCertificate(certPEM, keyPEM []byte) ClientOption {
+	return func(cfg *ClientConfig) {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			cfg.setErr(fmt.Errorf("failed to parse client certificate: %w", err))
+			return
+		}
+		cfg.tlsConfig().Certificates = []tls.Certificate{cert}
+	}
+}
+
+// WithClientCertificateFiles is a predefined option to authenticate Client with certificate in mutual TLS.
+// Certificate and key are loaded from files, and they are loaded again on TLS handshake when any of the files
+// has been modified, so rotated certificates are used without Client recreation.
+// If rotated files can't be loaded, previously loaded certificate is used
+func WithClientCertificateFiles(certFile, keyFile string) ClientOption {
+	return func(cfg *ClientConfig) {
+		reloader := &certificateReloader{certFile: certFile, keyFile: keyFile}
+		if err := reloader.reload(); err != nil {
+			cfg.setErr(err)
+			return
+		}
+		cfg.tlsConfig().GetClientCertificate = reloader.getClientCertificate
+	}
+}
+
+// WithRootCAs is a predefined option to verify api server certificate with custom certificate authorities instead of
+// system ones. caPEM must contain one or more PEM encoded certificates
+func WithRootCAs(caPEM []byte) ClientOption {
+	return func(cfg *ClientConfig) {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			cfg.setErr(errors.New("failed to parse root CAs: no valid certificates found"))
+			return
+		}
+		cfg.tlsConfig().RootCAs = pool
+	}
+}
+
+func (cfg *ClientConfig) tlsConfig() *tls.Config {
+	if cfg.TLSConfig == nil {
+		cfg.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return cfg.TLSConfig
+}
+
+func applyTLSConfig(httpClient *http.Client, tlsConfig *tls.Config) (*http.Client, error) {
+	if tlsConfig == nil {
+		return httpClient, nil
+	}
+
+	var transport *http.Transport
+	switch t := httpClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return nil, fmt.Errorf("TLS options can't be applied to http.Client with transport of type %T", t)
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	tlsClient := *httpClient
+	tlsClient.Transport = transport
+	return &tlsClient, nil
+}
+
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mu          sync.Mutex
+	cert        *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+}
+
+func (r *certificateReloader) getClientCertificate(_ *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.modified() {
+		// previous certificate is still valid until it expires, so failed reload (i.e. files are being written)
+		// shouldn't break connections
+		_ = r.load()
+	}
+	return r.cert, nil
+}
+
+func (r *certificateReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.load()
+}
+
+func (r *certificateReloader) modified() bool {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return false
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return false
+	}
+	return !certInfo.ModTime().Equal(r.certModTime) || !keyInfo.ModTime().Equal(r.keyModTime)
+}
+
+func (r *certificateReloader) load() error {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return fmt.Errorf("failed to read client certificate file: %w", err)
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read client key file: %w", err)
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	r.cert = &cert
+	r.certModTime = certInfo.ModTime()
+	r.keyModTime = keyInfo.ModTime()
+	return nil
+}
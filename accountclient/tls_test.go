@@ -0,0 +1,217 @@
+package accountclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func (s *accountAPIClientSuite) newTestCertificate(commonName string, parent *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	parentCert, parentKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	s.Require().NoError(err)
+	cert, err := x509.ParseCertificate(der)
+	s.Require().NoError(err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	s.Require().NoError(err)
+
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (s *accountAPIClientSuite) newMutualTLSServer(ca *testCertificate, clientNames *[]string) *httptest.Server {
+	serverCert := s.newTestCertificate("server", ca)
+	serverKeyPair, err := tls.X509KeyPair(serverCert.certPEM, serverCert.keyPEM)
+	s.Require().NoError(err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	testServ := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*clientNames = append(*clientNames, r.TLS.PeerCertificates[0].Subject.CommonName)
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	testServ.TLS = &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{serverKeyPair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	testServ.StartTLS()
+	return testServ
+}
+
+func (s *accountAPIClientSuite) TestTLSOptions() {
+	s.Run("should authenticate with client certificate and verify server with custom root CA", func() {
+		// given
+		ca := s.newTestCertificate("ca", nil)
+		clientCert := s.newTestCertificate("client", ca)
+		clientNames := make([]string, 0)
+		testServ := s.newMutualTLSServer(ca, &clientNames)
+		defer testServ.Close()
+
+		httpClient := &http.Client{Timeout: time.Second * 5}
+		accountsClient, err := NewAccountClient(testServ.URL,
+			WithCustomHTTPClient(httpClient),
+			WithRootCAs(ca.certPEM),
+			WithClientCertificate(clientCert.certPEM, clientCert.keyPEM))
+		s.Require().NoError(err)
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New())
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal([]string{"client"}, clientNames)
+		s.Assert().Equal(time.Second*5, accountsClient.httpClient.Timeout)
+		s.Assert().Nil(httpClient.Transport)
+	})
+
+	s.Run("should use rotated client certificate files", func() {
+		// given
+		ca := s.newTestCertificate("ca", nil)
+		clientNames := make([]string, 0)
+		testServ := s.newMutualTLSServer(ca, &clientNames)
+		defer testServ.Close()
+
+		dir := s.T().TempDir()
+		certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+		writeCertificate := func(cert *testCertificate, modTime time.Time) {
+			s.Require().NoError(os.WriteFile(certFile, cert.certPEM, 0o600))
+			s.Require().NoError(os.WriteFile(keyFile, cert.keyPEM, 0o600))
+			s.Require().NoError(os.Chtimes(certFile, modTime, modTime))
+			s.Require().NoError(os.Chtimes(keyFile, modTime, modTime))
+		}
+		writeCertificate(s.newTestCertificate("client-1", ca), time.Now().Add(-time.Minute))
+
+		accountsClient, err := NewAccountClient(testServ.URL,
+			WithRootCAs(ca.certPEM),
+			WithClientCertificateFiles(certFile, keyFile))
+		s.Require().NoError(err)
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New())
+		s.Require().NoError(err)
+
+		// when
+		writeCertificate(s.newTestCertificate("client-2", ca), time.Now())
+		accountsClient.httpClient.CloseIdleConnections()
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New())
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal([]string{"client-1", "client-2"}, clientNames)
+	})
+
+	s.Run("should keep client certificate and root CA of options passed before TLS config", func() {
+		// given
+		ca := s.newTestCertificate("ca", nil)
+		clientCert := s.newTestCertificate("client", ca)
+		clientNames := make([]string, 0)
+		testServ := s.newMutualTLSServer(ca, &clientNames)
+		defer testServ.Close()
+
+		accountsClient, err := NewAccountClient(testServ.URL,
+			WithRootCAs(ca.certPEM),
+			WithClientCertificate(clientCert.certPEM, clientCert.keyPEM),
+			WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS13}))
+		s.Require().NoError(err)
+
+		// when
+		_, err = accountsClient.FetchAccount(context.Background(), uuid.New())
+
+		// then
+		s.Require().NoError(err)
+		s.Assert().Equal([]string{"client"}, clientNames)
+	})
+
+	s.Run("should not create client when TLS config overrides previous TLS options", func() {
+		// given
+		ca := s.newTestCertificate("ca", nil)
+		clientCert := s.newTestCertificate("client", ca)
+		keyPair, err := tls.X509KeyPair(clientCert.certPEM, clientCert.keyPEM)
+		s.Require().NoError(err)
+		testCases := map[string][]ClientOption{
+			"client certificate": {
+				WithClientCertificate(clientCert.certPEM, clientCert.keyPEM),
+				WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{keyPair}}),
+			},
+			"root CAs": {
+				WithRootCAs(ca.certPEM),
+				WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12, RootCAs: x509.NewCertPool()}),
+			},
+		}
+
+		for name, options := range testCases {
+			s.Run(name, func() {
+				// when
+				accountsClient, err := NewAccountClient("https://some-api.com", options...)
+
+				// then
+				s.Assert().ErrorContains(err, "TLS config overrides")
+				s.Assert().Nil(accountsClient)
+			})
+		}
+	})
+
+	s.Run("should not create client when certificates are invalid", func() {
+		testCases := map[string]ClientOption{
+			"invalid client certificate":      WithClientCertificate([]byte("invalid"), []byte("invalid")),
+			"missing client certificate file": WithClientCertificateFiles("missing.crt", "missing.key"),
+			"invalid root CAs":                WithRootCAs([]byte("invalid")),
+		}
+
+		for name, option := range testCases {
+			s.Run(name, func() {
+				// when
+				accountsClient, err := NewAccountClient("https://some-api.com", option)
+
+				// then
+				s.Assert().Error(err)
+				s.Assert().Nil(accountsClient)
+			})
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/backup"
+	"github.com/arturskrzydlo/account-api-client/accountclient/importer"
+)
+
+func runExport(ctx context.Context, a *app, args []string) error {
+	var cfg clientConfig
+	fs, err := a.newFlagSet("export", "<archive>", &cfg)
+	if err != nil {
+		return err
+	}
+	organisationID := fs.String("organisation-id", "", "organisation which accounts are exported")
+	if err = parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	if *organisationID == "" {
+		return usageErr("--organisation-id is required")
+	}
+	exportedOrganisationID, err := optionalUUID("organisation-id", *organisationID)
+	if err != nil {
+		return err
+	}
+
+	client, p, err := a.setup(&cfg)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	manifest, err := backup.Export(ctx, client, exportedOrganisationID, file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write archive: %w", closeErr)
+	}
+	if err != nil {
+		_ = os.Remove(fs.Arg(0))
+		return err
+	}
+	return printManifest(p, manifest)
+}
+
+func runRestore(ctx context.Context, a *app, args []string) error {
+	var cfg clientConfig
+	fs, err := a.newFlagSet("restore", "<archive>", &cfg)
+	if err != nil {
+		return err
+	}
+	organisationID := fs.String("organisation-id", "",
+		"organisation of restored accounts, organisation from archive is used when it's not set")
+	concurrency := fs.Int("concurrency", 4, "number of accounts created at the same time")
+	checkpointPath := fs.String("checkpoint", "",
+		"file storing ids of restored accounts, restore run again with the same checkpoint skips them")
+	reportPath := fs.String("report", "", "CSV file with result of each restored account")
+	dryRun := fs.Bool("dry-run", false, "only verify archive and print its manifest")
+	if err = parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	targetOrganisationID, err := optionalUUID("organisation-id", *organisationID)
+	if err != nil {
+		return err
+	}
+
+	client, p, err := a.setup(&cfg)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	if *dryRun {
+		manifest, manifestErr := backup.ReadManifest(file)
+		if manifestErr != nil {
+			return manifestErr
+		}
+		return printManifest(p, manifest)
+	}
+
+	options := backup.RestoreOptions{
+		OrganisationID: targetOrganisationID,
+		ImportOptions:  []importer.Option{importer.WithConcurrency(*concurrency)},
+	}
+	if *checkpointPath != "" {
+		options.ImportOptions = append(options.ImportOptions, importer.WithCheckpoint(*checkpointPath))
+	}
+	if *reportPath != "" {
+		report, createErr := os.Create(*reportPath)
+		if createErr != nil {
+			return fmt.Errorf("failed to create restore report: %w", createErr)
+		}
+		defer report.Close()
+		options.ImportOptions = append(options.ImportOptions, importer.WithReport(report))
+	}
+
+	_, summary, restoreErr := backup.Restore(ctx, client, file, options)
+	if err = printImportSummary(p, summary); err != nil {
+		return err
+	}
+	if restoreErr != nil {
+		return restoreErr
+	}
+	if notRestored := summary.Invalid + summary.Failed; notRestored > 0 {
+		return fmt.Errorf("%d accounts haven't been restored", notRestored)
+	}
+	return nil
+}
+
+func printManifest(p *printer, manifest *backup.Manifest) error {
+	return p.summary(manifest, [][2]string{
+		{"format version", strconv.Itoa(manifest.FormatVersion)},
+		{"organisation id", manifest.OrganisationID.String()},
+		{"exported at", manifest.ExportedAt.Format(time.RFC3339)},
+		{"accounts", strconv.Itoa(manifest.AccountCount)},
+		{"accounts sha256", manifest.AccountsSHA256},
+	})
+}
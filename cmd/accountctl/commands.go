@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient"
+	"github.com/arturskrzydlo/account-api-client/accountclient/importer"
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+// listValue is flag which can be repeated or contain comma separated values
+type listValue []string
+
+func (l *listValue) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listValue) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// newFlagSet creates flag set of command with common flags registered in cfg
+func (a *app) newFlagSet(name, arguments string, cfg *clientConfig) (*flag.FlagSet, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: accountctl %s [flags] %s\n\nFlags:\n", name, arguments)
+		fs.PrintDefaults()
+	}
+	if err := cfg.register(fs, a.getenv); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// parseFlags parses args and checks that exactly expectedArgs positional arguments remained
+func parseFlags(fs *flag.FlagSet, args []string, expectedArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageErr("%s", err)
+	}
+	if fs.NArg() != expectedArgs {
+		fs.Usage()
+		return usageErr("expected %d arguments, got %d", expectedArgs, fs.NArg())
+	}
+	return nil
+}
+
+// setup creates client and printer configured with common flags
+func (a *app) setup(cfg *clientConfig) (*accountclient.Client, *printer, error) {
+	p, err := newPrinter(cfg.output, a.stdout)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := cfg.newClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, p, nil
+}
+
+func parseAccountID(value string) (uuid.UUID, error) {
+	accountID, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, usageErr("invalid account id %q", value)
+	}
+	return accountID, nil
+}
+
+func runCreate(ctx context.Context, a *app, args []string) error {
+	var cfg clientConfig
+	fs, err := a.newFlagSet("create", "", &cfg)
+	if err != nil {
+		return err
+	}
+	commonFlags := flagNames(fs)
+	var names, alternativeNames listValue
+	file := fs.String("file", "", "JSON file with create account request, - reads it from stdin. Can't be used with account flags")
+	id := fs.String("id", "", "account id, random id is generated when it's not set")
+	organisationID := fs.String("organisation-id", "", "organisation id")
+	country := fs.String("country", "", "ISO 3166-1 code of country of account, i.e. GB")
+	fs.Var(&names, "name", "name of account holder, can be repeated or comma separated")
+	fs.Var(&alternativeNames, "alternative-name", "alternative name of account holder, can be repeated or comma separated")
+	bankID := fs.String("bank-id", "", "local country bank identifier")
+	bankIDCode := fs.String("bank-id-code", "", "type of bank id, i.e. GBDSC")
+	bic := fs.String("bic", "", "SWIFT BIC")
+	accountNumber := fs.String("account-number", "", "account number, generated by api when it's not set")
+	iban := fs.String("iban", "", "IBAN, generated by api when it's not set")
+	baseCurrency := fs.String("base-currency", "", "ISO 4217 code of account currency")
+	classification := fs.String("classification", "", "account classification: Personal or Business")
+	if err = parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	var request *models.CreateAccountRequest
+	if *file != "" {
+		accountFlags := make([]string, 0)
+		fs.Visit(func(f *flag.Flag) {
+			if !commonFlags[f.Name] && f.Name != "file" {
+				accountFlags = append(accountFlags, "--"+f.Name)
+			}
+		})
+		if len(accountFlags) > 0 {
+			return usageErr("--file can't be used with flags %s", strings.Join(accountFlags, ", "))
+		}
+		if request, err = readCreateRequest(*file, a.stdin); err != nil {
+			return err
+		}
+	} else {
+		attributes := &models.CreateAccountAttributes{
+			AccountNumber:    *accountNumber,
+			AlternativeNames: alternativeNames,
+			BankID:           *bankID,
+			BankIDCode:       *bankIDCode,
+			BaseCurrency:     *baseCurrency,
+			Bic:              *bic,
+			Iban:             *iban,
+			Name:             names,
+		}
+		if *country != "" {
+			attributes.Country = country
+		}
+		if *classification != "" {
+			attributes.AccountClassification = classification
+		}
+		request = &models.CreateAccountRequest{Data: &models.CreateAccountData{Attributes: attributes, Type: importer.AccountType}}
+		if request.Data.ID, err = optionalUUID("id", *id); err != nil {
+			return err
+		}
+		if request.Data.OrganisationID, err = optionalUUID("organisation-id", *organisationID); err != nil {
+			return err
+		}
+	}
+	if request.Data == nil {
+		return errors.New("create account request has no data")
+	}
+	if request.Data.ID == uuid.Nil {
+		request.Data.ID = uuid.New()
+	}
+
+	client, p, err := a.setup(&cfg)
+	if err != nil {
+		return err
+	}
+	account, err := client.CreateAccount(ctx, request)
+	if err != nil {
+		return err
+	}
+	return p.account(account.Data)
+}
+
+func runGet(ctx context.Context, a *app, args []string) error {
+	var cfg clientConfig
+	fs, err := a.newFlagSet("get", "<account-id>", &cfg)
+	if err != nil {
+		return err
+	}
+	if err = parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	accountID, err := parseAccountID(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	client, p, err := a.setup(&cfg)
+	if err != nil {
+		return err
+	}
+	account, err := client.FetchAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	return p.account(account.Data)
+}
+
+func runDelete(ctx context.Context, a *app, args []string) error {
+	var cfg clientConfig
+	fs, err := a.newFlagSet("delete", "<account-id>", &cfg)
+	if err != nil {
+		return err
+	}
+	version := fs.Int64("version", -1, "version of account, current version is fetched when it's not set")
+	if err = parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	accountID, err := parseAccountID(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	client, _, err := a.setup(&cfg)
+	if err != nil {
+		return err
+	}
+	if *version < 0 {
+		account, fetchErr := client.FetchAccount(ctx, accountID)
+		if fetchErr != nil {
+			return fmt.Errorf("failed to fetch version of account: %w", fetchErr)
+		}
+		if account.Data.Version == nil {
+			return errors.New("account fetched from api has no version")
+		}
+		version = account.Data.Version
+	}
+	if err = client.DeleteAccount(ctx, accountID, version); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "account %s deleted\n", accountID)
+	return nil
+}
+
+func runList(ctx context.Context, a *app, args []string) error {
+	var cfg clientConfig
+	fs, err := a.newFlagSet("list", "", &cfg)
+	if err != nil {
+		return err
+	}
+	filters := map[string]*listValue{
+		accountclient.FilterOrganisationID: {},
+		accountclient.FilterAccountNumber:  {},
+		accountclient.FilterBankID:         {},
+		accountclient.FilterBankIDCode:     {},
+		accountclient.FilterCountry:        {},
+		accountclient.FilterIban:           {},
+	}
+	for field, value := range filters {
+		fs.Var(value, strings.ReplaceAll(field, "_", "-"), "list accounts with any of given "+field+" values, can be repeated or comma separated")
+	}
+	pageNumber := fs.Int("page-number", 0, "zero based number of listed page")
+	pageSize := fs.Int("page-size", 0, "number of accounts on page, api default is used when it's not set")
+	all := fs.Bool("all", false, "list accounts from all pages starting from --page-number")
+	if err = parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	listOptions := accountclient.ListOptions{
+		PageNumber: *pageNumber,
+		PageSize:   *pageSize,
+		Filters:    make(map[string][]string),
+	}
+	for field, value := range filters {
+		if len(*value) > 0 {
+			listOptions.Filters[field] = *value
+		}
+	}
+
+	client, p, err := a.setup(&cfg)
+	if err != nil {
+		return err
+	}
+	accounts := make([]*models.AccountDataResponse, 0)
+	for {
+		page, listErr := client.ListAccounts(ctx, listOptions)
+		if listErr != nil {
+			return listErr
+		}
+		accounts = append(accounts, page.Data...)
+		if !*all || page.Links == nil || page.Links.Next == "" || len(page.Data) == 0 {
+			break
+		}
+		listOptions.PageNumber++
+	}
+	return p.accounts(accounts)
+}
+
+// flagNames returns names of flags registered in fs
+func flagNames(fs *flag.FlagSet) map[string]bool {
+	names := make(map[string]bool)
+	fs.VisitAll(func(f *flag.Flag) {
+		names[f.Name] = true
+	})
+	return names
+}
+
+func optionalUUID(flagName, value string) (uuid.UUID, error) {
+	if value == "" {
+		return uuid.Nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, usageErr("invalid --%s %q", flagName, value)
+	}
+	return id, nil
+}
+
+// readCreateRequest reads models.CreateAccountRequest from JSON file, or from stdin when path is -
+func readCreateRequest(path string, stdin io.Reader) (*models.CreateAccountRequest, error) {
+	var (
+		content []byte
+		err     error
+	)
+	if path == "-" {
+		content, err = io.ReadAll(stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read create account request: %w", err)
+	}
+
+	var request models.CreateAccountRequest
+	if err = json.Unmarshal(content, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshall create account request: %w", err)
+	}
+	return &request, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient"
+)
+
+const (
+	envPrefix          = "ACCOUNTCTL_"
+	defaultBaseURL     = "http://localhost:8080/v1"
+	defaultTimeout     = time.Second * 10
+	defaultBackoff     = backoffNone
+	defaultBackoffBase = time.Millisecond * 100
+	defaultMultiplier  = 2
+
+	backoffNone        = "none"
+	backoffLinear      = "linear"
+	backoffExponential = "exponential"
+)
+
+// clientConfig contains flags common for all commands. Their defaults are taken from ACCOUNTCTL_* environment variables
+type clientConfig struct {
+	baseURL           string
+	output            string
+	timeout           time.Duration
+	maxRetries        int
+	backoff           string
+	backoffDelay      time.Duration
+	backoffMultiplier int
+}
+
+// register adds common flags to fs. Error is returned when environment variable has invalid value
+func (c *clientConfig) register(fs *flag.FlagSet, getenv func(key string) string) error {
+	env := func(name string) string {
+		return getenv(envName(name))
+	}
+
+	// first invalid environment variable is reported
+	var envErr error
+	setErr := func(name string, err error) {
+		if envErr == nil {
+			envErr = fmt.Errorf("invalid %s: %w", envName(name), err)
+		}
+	}
+	stringValue := func(name, defaultValue string) string {
+		if value := env(name); value != "" {
+			return value
+		}
+		return defaultValue
+	}
+	durationValue := func(name string, defaultValue time.Duration) time.Duration {
+		value := env(name)
+		if value == "" {
+			return defaultValue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			setErr(name, err)
+		}
+		return parsed
+	}
+	intValue := func(name string, defaultValue int) int {
+		value := env(name)
+		if value == "" {
+			return defaultValue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			setErr(name, err)
+		}
+		return parsed
+	}
+
+	fs.StringVar(&c.baseURL, "base-url", stringValue("base-url", defaultBaseURL), "base url of account api")
+	fs.StringVar(&c.output, "output", stringValue("output", formatTable), "output format: table, json or yaml")
+	fs.DurationVar(&c.timeout, "timeout", durationValue("timeout", defaultTimeout), "timeout of a single request")
+	fs.IntVar(&c.maxRetries, "max-retries", intValue("max-retries", 0),
+		"number of retries of requests which failed with network error or 5xx status code")
+	fs.StringVar(&c.backoff, "backoff", stringValue("backoff", defaultBackoff),
+		"delay strategy between retries: none, linear or exponential")
+	fs.DurationVar(&c.backoffDelay, "backoff-delay", durationValue("backoff-delay", defaultBackoffBase),
+		"delay between retries, initial delay for exponential backoff")
+	fs.IntVar(&c.backoffMultiplier, "backoff-multiplier", intValue("backoff-multiplier", defaultMultiplier),
+		"multiplier of exponential backoff")
+
+	return envErr
+}
+
+// envName returns name of environment variable with default value of flag
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// newClient creates accountclient.Client configured with common flags
+func (c *clientConfig) newClient() (*accountclient.Client, error) {
+	options := []accountclient.ClientOption{
+		accountclient.WithCustomHTTPClient(&http.Client{Timeout: c.timeout}),
+		accountclient.WithRetriesOnDefaultRetryPolicy(c.maxRetries),
+		accountclient.WithUserAgent("accountctl"),
+	}
+	switch c.backoff {
+	case backoffNone:
+	case backoffLinear:
+		options = append(options, accountclient.WithLinearBackoffStrategy(c.backoffDelay))
+	case backoffExponential:
+		options = append(options, accountclient.WithExponentialBackoffStrategy(c.backoffDelay, c.backoffMultiplier))
+	default:
+		return nil, usageErr("unknown backoff %q", c.backoff)
+	}
+
+	client, err := accountclient.NewAccountClient(c.baseURL, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account client: %w", err)
+	}
+	return client, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/importer"
+)
+
+// number of imported rows after which progress is printed
+const progressInterval = 100
+
+type importSummary struct {
+	Total   int `json:"total"`
+	Created int `json:"created"`
+	Exists  int `json:"exists"`
+	Skipped int `json:"skipped"`
+	Invalid int `json:"invalid"`
+	Failed  int `json:"failed"`
+}
+
+func runImport(ctx context.Context, a *app, args []string) error {
+	var cfg clientConfig
+	fs, err := a.newFlagSet("import", "<file>", &cfg)
+	if err != nil {
+		return err
+	}
+	format := fs.String("format", "", "format of file: csv or jsonl, detected from file extension when it's not set")
+	concurrency := fs.Int("concurrency", 4, "number of accounts created at the same time")
+	checkpointPath := fs.String("checkpoint", "",
+		"file storing ids of imported accounts, import run again with the same checkpoint skips them")
+	reportPath := fs.String("report", "", "CSV file with result of each row")
+	if err = parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+
+	path := fs.Arg(0)
+	sourceFormat := importer.Format(*format)
+	if sourceFormat == "" {
+		if sourceFormat, err = importer.FormatFromPath(path); err != nil {
+			return usageErr("%s", err)
+		}
+	}
+
+	client, p, err := a.setup(&cfg)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open imported file: %w", err)
+	}
+	defer file.Close()
+	source, err := importer.NewSource(file, sourceFormat)
+	if err != nil {
+		return err
+	}
+
+	options := []importer.Option{
+		importer.WithConcurrency(*concurrency),
+		importer.WithProgress(func(summary importer.Summary) {
+			if summary.Total%progressInterval == 0 {
+				fmt.Fprintf(a.stderr, "imported %d rows\n", summary.Total)
+			}
+		}),
+	}
+	if *checkpointPath != "" {
+		options = append(options, importer.WithCheckpoint(*checkpointPath))
+	}
+	var report io.WriteCloser
+	if *reportPath != "" {
+		if report, err = os.Create(*reportPath); err != nil {
+			return fmt.Errorf("failed to create import report: %w", err)
+		}
+		defer report.Close()
+		options = append(options, importer.WithReport(report))
+	}
+
+	summary, importErr := importer.New(client, options...).Import(ctx, source)
+	if err = printImportSummary(p, summary); err != nil {
+		return err
+	}
+	if importErr != nil {
+		return importErr
+	}
+	if notImported := summary.Invalid + summary.Failed; notImported > 0 {
+		return fmt.Errorf("%d rows haven't been imported", notImported)
+	}
+	return nil
+}
+
+func printImportSummary(p *printer, summary importer.Summary) error {
+	return p.summary(importSummary(summary), [][2]string{
+		{"total", strconv.Itoa(summary.Total)},
+		{"created", strconv.Itoa(summary.Created)},
+		{"exists", strconv.Itoa(summary.Exists)},
+		{"skipped", strconv.Itoa(summary.Skipped)},
+		{"invalid", strconv.Itoa(summary.Invalid)},
+		{"failed", strconv.Itoa(summary.Failed)},
+	})
+}
@@ -0,0 +1,128 @@
+// Command accountctl allows to inspect and modify accounts in account api without writing Go code
+//
+// Usage:
+//
+//	accountctl <command> [flags] [arguments]
+//
+// Commands:
+//
+//	create   creates account from flags or JSON file
+//	get      fetches account by id
+//	delete   deletes account by id, version is fetched when it's not provided
+//	list     lists accounts matching filters
+//	import   creates accounts from CSV or JSON Lines file
+//	export   exports all accounts of organisation to archive
+//	restore  restores accounts from archive created by export
+//	plan     shows changes needed to reach desired state from YAML or JSON file
+//	apply    makes changes needed to reach desired state from YAML or JSON file
+//
+// Flags common for all commands can be also set with environment variables, i.e. --base-url with ACCOUNTCTL_BASE_URL.
+// Run accountctl <command> --help to see all flags of a command
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// errUsage is returned by commands which have been called with invalid flags or arguments
+var errUsage = errors.New("invalid usage")
+
+// app holds streams and environment used by commands, so they can be replaced in tests
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(key string) string
+}
+
+type command struct {
+	summary string
+	run     func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = map[string]command{
+	"create":  {summary: "creates account from flags or JSON file", run: runCreate},
+	"get":     {summary: "fetches account by id", run: runGet},
+	"delete":  {summary: "deletes account by id, version is fetched when it's not provided", run: runDelete},
+	"list":    {summary: "lists accounts matching filters", run: runList},
+	"import":  {summary: "creates accounts from CSV or JSON Lines file", run: runImport},
+	"export":  {summary: "exports all accounts of organisation to archive", run: runExport},
+	"restore": {summary: "restores accounts from archive created by export", run: runRestore},
+	"plan":    {summary: "shows changes needed to reach desired state from YAML or JSON file", run: runPlan},
+	"apply":   {summary: "makes changes needed to reach desired state from YAML or JSON file", run: runApply},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	a := &app{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}
+	code := a.run(ctx, os.Args[1:])
+	stop()
+	os.Exit(code)
+}
+
+// run runs command given in args and returns exit code
+func (a *app) run(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		a.usage()
+		return exitUsage
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		a.usage()
+		return exitOK
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(a.stderr, "accountctl: unknown command %q\n", args[0])
+		a.usage()
+		return exitUsage
+	}
+
+	err := cmd.run(ctx, a, args[1:])
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(a.stderr, "accountctl %s: %s\n", args[0], err)
+		return exitUsage
+	default:
+		fmt.Fprintf(a.stderr, "accountctl %s: %s\n", args[0], err)
+		return exitError
+	}
+}
+
+func (a *app) usage() {
+	fmt.Fprintln(a.stderr, "Usage: accountctl <command> [flags] [arguments]")
+	fmt.Fprintln(a.stderr)
+	fmt.Fprintln(a.stderr, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(a.stderr, "  %-8s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(a.stderr)
+	fmt.Fprintln(a.stderr, "Run accountctl <command> --help to see flags of a command")
+}
+
+// usageErr wraps errUsage with message describing invalid usage
+func usageErr(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/yaml.v3"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/accounttest"
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+type accountctlSuite struct {
+	suite.Suite
+
+	fakeAPI *accounttest.Server
+	env     map[string]string
+}
+
+func TestAccountctl(t *testing.T) {
+	suite.Run(t, &accountctlSuite{})
+}
+
+func (s *accountctlSuite) SetupTest() {
+	s.fakeAPI = accounttest.NewServer()
+	s.env = map[string]string{"ACCOUNTCTL_BASE_URL": s.fakeAPI.BaseURL()}
+}
+
+func (s *accountctlSuite) TearDownTest() {
+	s.fakeAPI.Close()
+}
+
+// run runs accountctl with args and returns exit code with stdout and stderr
+func (s *accountctlSuite) run(stdin string, args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	a := &app{
+		stdin:  strings.NewReader(stdin),
+		stdout: &out,
+		stderr: &errOut,
+		getenv: func(key string) string { return s.env[key] },
+	}
+	code = a.run(context.Background(), args)
+	return code, out.String(), errOut.String()
+}
+
+func (s *accountctlSuite) createAccount(args ...string) *models.AccountDataResponse {
+	code, stdout, stderr := s.run("", append([]string{"create", "--output", "json"}, args...)...)
+	s.Require().Equal(exitOK, code, stderr)
+	var account models.AccountDataResponse
+	s.Require().NoError(json.Unmarshal([]byte(stdout), &account))
+	return &account
+}
+
+func (s *accountctlSuite) TestCreate() {
+	s.Run("should create account from flags", func() {
+		// given
+		organisationID := uuid.New()
+
+		// when
+		account := s.createAccount("--organisation-id", organisationID.String(), "--country", "GB",
+			"--name", "Samantha Holder", "--bank-id", "400300")
+
+		// then
+		s.Assert().Equal(organisationID, account.OrganisationID)
+		s.Assert().Equal("400300", account.Attributes.BankID)
+		s.Assert().Equal([]string{"Samantha Holder"}, account.Attributes.Name)
+		s.Assert().Len(s.fakeAPI.Accounts(), 1)
+	})
+
+	s.Run("should create account from JSON file", func() {
+		// given
+		accountID := uuid.New()
+		request := `{"data":{"id":"` + accountID.String() + `","organisation_id":"` + uuid.NewString() +
+			`","type":"accounts","attributes":{"country":"FR","name":["Jean Dupont"]}}}`
+		path := filepath.Join(s.T().TempDir(), "account.json")
+		s.Require().NoError(os.WriteFile(path, []byte(request), 0o600))
+
+		// when
+		account := s.createAccount("--file", path)
+
+		// then
+		s.Assert().Equal(accountID, account.ID)
+		s.Assert().Equal("FR", *account.Attributes.Country)
+	})
+
+	s.Run("should not allow to mix JSON file with account flags", func() {
+		// when
+		code, _, stderr := s.run("{}", "create", "--file", "-", "--country", "GB")
+
+		// then
+		s.Assert().Equal(exitUsage, code)
+		s.Assert().Contains(stderr, "--country")
+	})
+
+	s.Run("should report api validation error", func() {
+		// when
+		code, _, stderr := s.run("", "create", "--organisation-id", uuid.NewString(), "--name", "Samantha Holder")
+
+		// then
+		s.Assert().Equal(exitError, code)
+		s.Assert().Contains(stderr, "country")
+	})
+}
+
+func (s *accountctlSuite) TestGetAndDelete() {
+	s.Run("should print fetched account as table", func() {
+		// given
+		account := s.createAccount("--organisation-id", uuid.NewString(), "--country", "GB", "--name", "Samantha Holder")
+
+		// when
+		code, stdout, stderr := s.run("", "get", account.ID.String())
+
+		// then
+		s.Require().Equal(exitOK, code, stderr)
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		s.Require().Len(lines, 2)
+		s.Assert().True(strings.HasPrefix(lines[0], "ID"))
+		s.Assert().Contains(lines[1], account.ID.String())
+		s.Assert().Contains(lines[1], "Samantha Holder")
+	})
+
+	s.Run("should print fetched account as yaml with json field names", func() {
+		// given
+		account := s.createAccount("--organisation-id", uuid.NewString(), "--country", "GB", "--name", "Samantha Holder")
+
+		// when
+		code, stdout, stderr := s.run("", "get", "--output", "yaml", account.ID.String())
+
+		// then
+		s.Require().Equal(exitOK, code, stderr)
+		var fetched map[string]interface{}
+		s.Require().NoError(yaml.Unmarshal([]byte(stdout), &fetched))
+		s.Assert().Equal(account.ID.String(), fetched["id"])
+		s.Assert().Equal(account.OrganisationID.String(), fetched["organisation_id"])
+	})
+
+	s.Run("should delete account fetching its version", func() {
+		// given
+		account := s.createAccount("--organisation-id", uuid.NewString(), "--country", "GB", "--name", "Samantha Holder")
+
+		// when
+		code, stdout, stderr := s.run("", "delete", account.ID.String())
+
+		// then
+		s.Require().Equal(exitOK, code, stderr)
+		s.Assert().Contains(stdout, account.ID.String())
+		code, _, stderr = s.run("", "get", account.ID.String())
+		s.Assert().Equal(exitError, code)
+		s.Assert().Contains(stderr, "404")
+	})
+
+	s.Run("should not delete account with version which doesn't match", func() {
+		// given
+		account := s.createAccount("--organisation-id", uuid.NewString(), "--country", "GB", "--name", "Samantha Holder")
+
+		// when
+		code, _, stderr := s.run("", "delete", "--version", "3", account.ID.String())
+
+		// then
+		s.Assert().Equal(exitError, code)
+		s.Assert().Contains(stderr, "409")
+	})
+
+	s.Run("should reject invalid account id", func() {
+		// when
+		code, _, stderr := s.run("", "get", "not-an-id")
+
+		// then
+		s.Assert().Equal(exitUsage, code)
+		s.Assert().Contains(stderr, "invalid account id")
+	})
+}
+
+func (s *accountctlSuite) TestList() {
+	organisationID := uuid.New()
+	for _, country := range []string{"GB", "FR", "DE"} {
+		s.createAccount("--organisation-id", organisationID.String(), "--country", country, "--name", "Holder")
+	}
+	s.createAccount("--organisation-id", uuid.NewString(), "--country", "GB", "--name", "Holder")
+
+	s.Run("should list accounts from all pages matching filters", func() {
+		// when
+		code, stdout, stderr := s.run("", "list", "--output", "json", "--organisation-id", organisationID.String(),
+			"--country", "GB,FR", "--page-size", "1", "--all")
+
+		// then
+		s.Require().Equal(exitOK, code, stderr)
+		var accounts []models.AccountDataResponse
+		s.Require().NoError(json.Unmarshal([]byte(stdout), &accounts))
+		s.Require().Len(accounts, 2)
+		s.Assert().Equal("GB", *accounts[0].Attributes.Country)
+		s.Assert().Equal("FR", *accounts[1].Attributes.Country)
+	})
+
+	s.Run("should list only selected page", func() {
+		// when
+		code, stdout, stderr := s.run("", "list", "--output", "json", "--page-size", "3", "--page-number", "1")
+
+		// then
+		s.Require().Equal(exitOK, code, stderr)
+		var accounts []models.AccountDataResponse
+		s.Require().NoError(json.Unmarshal([]byte(stdout), &accounts))
+		s.Assert().Len(accounts, 1)
+	})
+}
+
+func (s *accountctlSuite) TestConfiguration() {
+	s.Run("should report invalid environment variable", func() {
+		// given
+		s.env["ACCOUNTCTL_MAX_RETRIES"] = "many"
+
+		// when
+		code, _, stderr := s.run("", "list")
+
+		// then
+		s.Assert().Equal(exitError, code)
+		s.Assert().Contains(stderr, "ACCOUNTCTL_MAX_RETRIES")
+		delete(s.env, "ACCOUNTCTL_MAX_RETRIES")
+	})
+
+	s.Run("should prefer flags over environment variables", func() {
+		// given
+		s.env["ACCOUNTCTL_BASE_URL"] = "http://127.0.0.1:1/v1"
+
+		// when
+		code, _, stderr := s.run("", "list", "--base-url", s.fakeAPI.BaseURL(), "--backoff", "exponential")
+
+		// then
+		s.Assert().Equal(exitOK, code, stderr)
+	})
+
+	s.Run("should reject unknown output format and command", func() {
+		// when
+		code, _, _ := s.run("", "list", "--output", "xml")
+		unknownCode, _, stderr := s.run("", "update")
+
+		// then
+		s.Assert().Equal(exitUsage, code)
+		s.Assert().Equal(exitUsage, unknownCode)
+		s.Assert().Contains(stderr, "unknown command")
+	})
+}
+
+func (s *accountctlSuite) TestImport() {
+	s.Run("should import accounts and write report", func() {
+		// given
+		dir := s.T().TempDir()
+		path := filepath.Join(dir, "accounts.csv")
+		content := "id,organisation_id,country,name\n" +
+			uuid.NewString() + "," + uuid.NewString() + ",GB,Samantha Holder\n" +
+			uuid.NewString() + "," + uuid.NewString() + ",GB,\n"
+		s.Require().NoError(os.WriteFile(path, []byte(content), 0o600))
+		reportPath := filepath.Join(dir, "report.csv")
+
+		// when
+		code, stdout, stderr := s.run("", "import", "--output", "json", "--report", reportPath,
+			"--checkpoint", filepath.Join(dir, "checkpoint"), path)
+
+		// then
+		s.Assert().Equal(exitError, code)
+		s.Assert().Contains(stderr, "1 rows haven't been imported")
+		var summary importSummary
+		s.Require().NoError(json.Unmarshal([]byte(stdout), &summary))
+		s.Assert().Equal(importSummary{Total: 2, Created: 1, Invalid: 1}, summary)
+		report, err := os.ReadFile(reportPath)
+		s.Require().NoError(err)
+		s.Assert().Contains(string(report), "name is required")
+		s.Assert().Len(s.fakeAPI.Accounts(), 1)
+	})
+
+	s.Run("should require format of file without known extension", func() {
+		// when
+		code, _, stderr := s.run("", "import", "accounts.txt")
+
+		// then
+		s.Assert().Equal(exitUsage, code)
+		s.Assert().Contains(stderr, "unknown format")
+	})
+}
+
+func (s *accountctlSuite) TestExportAndRestore() {
+	s.Run("should export organisation and restore it in another organisation", func() {
+		// given
+		organisationID := uuid.New()
+		accountIDs := make([]uuid.UUID, 0)
+		for i := 0; i < 3; i++ {
+			accountIDs = append(accountIDs,
+				s.createAccount("--organisation-id", organisationID.String(), "--country", "GB", "--name", "Holder").ID)
+		}
+		archive := filepath.Join(s.T().TempDir(), "backup.tar.gz")
+		code, stdout, stderr := s.run("", "export", "--organisation-id", organisationID.String(), archive)
+		s.Require().Equal(exitOK, code, stderr)
+		s.Require().Contains(stdout, "accounts:")
+		target := accounttest.NewServer()
+		defer target.Close()
+		targetOrganisationID := uuid.New()
+
+		// when
+		code, stdout, stderr = s.run("", "restore", "--output", "json", "--base-url", target.BaseURL(),
+			"--organisation-id", targetOrganisationID.String(), archive)
+
+		// then
+		s.Require().Equal(exitOK, code, stderr)
+		var summary importSummary
+		s.Require().NoError(json.Unmarshal([]byte(stdout), &summary))
+		s.Assert().Equal(3, summary.Created)
+		restoredIDs := make([]uuid.UUID, 0)
+		for _, account := range target.Accounts() {
+			s.Assert().Equal(targetOrganisationID, account.OrganisationID)
+			restoredIDs = append(restoredIDs, account.ID)
+		}
+		s.Assert().ElementsMatch(accountIDs, restoredIDs)
+	})
+
+	s.Run("should require organisation to export", func() {
+		// when
+		code, _, _ := s.run("", "export", filepath.Join(s.T().TempDir(), "backup.tar.gz"))
+
+		// then
+		s.Assert().Equal(exitUsage, code)
+	})
+}
+
+func (s *accountctlSuite) TestPlanAndApply() {
+	writeDesiredState := func(organisationID uuid.UUID) (string, uuid.UUID) {
+		accountID := uuid.New()
+		content := fmt.Sprintf("%s:\n  organisation_id: %s\n  attributes:\n    country: GB\n    name: [Holder]\n",
+			accountID, organisationID)
+		path := filepath.Join(s.T().TempDir(), "accounts.yaml")
+		s.Require().NoError(os.WriteFile(path, []byte(content), 0o600))
+		return path, accountID
+	}
+
+	s.Run("should plan changes without making them", func() {
+		// given
+		path, accountID := writeDesiredState(uuid.New())
+
+		// when
+		code, stdout, stderr := s.run("", "plan", path)
+
+		// then
+		s.Require().Equal(exitOK, code, stderr)
+		s.Assert().Contains(stdout, "+ create account "+accountID.String())
+		s.Assert().Contains(stdout, "Plan: 1 to create, 0 to update, 0 to delete")
+		s.Assert().Empty(s.fakeAPI.Accounts())
+	})
+
+	s.Run("should apply changes when confirmed", func() {
+		// given
+		path, accountID := writeDesiredState(uuid.New())
+
+		// when
+		code, _, stderr := s.run("yes\n", "apply", path)
+
+		// then
+		s.Require().Equal(exitOK, code, stderr)
+		accounts := s.fakeAPI.Accounts()
+		s.Require().Len(accounts, 1)
+		s.Assert().Equal(accountID, accounts[0].ID)
+	})
+
+	s.Run("should not apply changes when not confirmed", func() {
+		// given
+		s.fakeAPI.Reset()
+		path, _ := writeDesiredState(uuid.New())
+
+		// when
+		code, _, _ := s.run("no\n", "apply", path)
+
+		// then
+		s.Assert().Equal(exitError, code)
+		s.Assert().Empty(s.fakeAPI.Accounts())
+	})
+
+	s.Run("should delete other accounts of organisation only with prune", func() {
+		// given
+		s.fakeAPI.Reset()
+		organisationID := uuid.New()
+		otherAccount := s.createAccount("--organisation-id", organisationID.String(), "--country", "GB", "--name", "Holder")
+		path, accountID := writeDesiredState(organisationID)
+
+		// when
+		code, stdout, stderr := s.run("", "apply", "--auto-approve", path)
+
+		// then
+		s.Require().Equal(exitOK, code, stderr)
+		s.Assert().Contains(stdout, "Plan: 1 to create, 0 to update, 0 to delete")
+		s.Assert().Len(s.fakeAPI.Accounts(), 2)
+
+		// when
+		code, stdout, stderr = s.run("", "apply", "--auto-approve", "--prune", path)
+
+		// then
+		s.Require().Equal(exitOK, code, stderr)
+		s.Assert().Contains(stdout, "- delete account "+otherAccount.ID.String())
+		accounts := s.fakeAPI.Accounts()
+		s.Require().Len(accounts, 1)
+		s.Assert().Equal(accountID, accounts[0].ID)
+	})
+
+	s.Run("should apply changes without confirmation when auto approved", func() {
+		// given
+		s.fakeAPI.Reset()
+		path, _ := writeDesiredState(uuid.New())
+
+		// when
+		code, _, stderr := s.run("", "apply", "--auto-approve", path)
+
+		// then
+		s.Require().Equal(exitOK, code, stderr)
+		s.Assert().Len(s.fakeAPI.Accounts(), 1)
+	})
+}
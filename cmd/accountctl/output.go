@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient/models"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+// printer writes accounts to out in selected format
+type printer struct {
+	format string
+	out    io.Writer
+}
+
+func newPrinter(format string, out io.Writer) (*printer, error) {
+	switch format {
+	case formatTable, formatJSON, formatYAML:
+		return &printer{format: format, out: out}, nil
+	default:
+		return nil, usageErr("unknown output format %q", format)
+	}
+}
+
+// account prints single account. In json and yaml formats it's printed as an object
+func (p *printer) account(account *models.AccountDataResponse) error {
+	if p.format == formatTable {
+		return p.table([]*models.AccountDataResponse{account})
+	}
+	return p.encode(account)
+}
+
+// accounts prints list of accounts. In json and yaml formats it's printed as an array
+func (p *printer) accounts(accounts []*models.AccountDataResponse) error {
+	if p.format == formatTable {
+		return p.table(accounts)
+	}
+	return p.encode(accounts)
+}
+
+// summary prints value describing result of a command. In table format rows with name and value are printed instead
+func (p *printer) summary(value interface{}, rows [][2]string) error {
+	if p.format != formatTable {
+		return p.encode(value)
+	}
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s:\t%s\n", row[0], row[1])
+	}
+	return w.Flush()
+}
+
+func (p *printer) encode(value interface{}) error {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize output: %w", err)
+	}
+	if p.format == formatYAML {
+		if content, err = jsonToYAML(content); err != nil {
+			return err
+		}
+	} else {
+		content = append(content, '\n')
+	}
+	_, err = p.out.Write(content)
+	return err
+}
+
+func (p *printer) table(accounts []*models.AccountDataResponse) error {
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tORGANISATION ID\tCOUNTRY\tBANK ID\tBANK ID CODE\tACCOUNT NUMBER\tIBAN\tNAME\tVERSION")
+	for _, account := range accounts {
+		attributes := account.Attributes
+		if attributes == nil {
+			attributes = &models.AccountAttributesResponse{}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			account.ID, account.OrganisationID, valueOrDash(attributes.Country), orDash(attributes.BankID),
+			orDash(attributes.BankIDCode), orDash(attributes.AccountNumber), orDash(attributes.Iban),
+			orDash(strings.Join(attributes.Name, " ")), versionOrDash(account.Version))
+	}
+	return w.Flush()
+}
+
+// jsonToYAML converts json to yaml preserving order of fields and their names from json tags
+func jsonToYAML(content []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, fmt.Errorf("failed to convert output to yaml: %w", err)
+	}
+	setBlockStyle(&node)
+	content, err := yaml.Marshal(&node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert output to yaml: %w", err)
+	}
+	return content, nil
+}
+
+// setBlockStyle removes json flow style and quoting, which yaml keeps after parsing json
+func setBlockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		setBlockStyle(child)
+	}
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func valueOrDash(value *string) string {
+	if value == nil {
+		return "-"
+	}
+	return orDash(*value)
+}
+
+func versionOrDash(version *int64) string {
+	if version == nil {
+		return "-"
+	}
+	return strconv.FormatInt(*version, 10)
+}
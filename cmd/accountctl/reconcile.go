@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/arturskrzydlo/account-api-client/accountclient"
+	"github.com/arturskrzydlo/account-api-client/accountclient/reconcile"
+)
+
+func runPlan(ctx context.Context, a *app, args []string) error {
+	_, plan, p, err := a.preparePlan(ctx, "plan", args, nil)
+	if err != nil {
+		return err
+	}
+	return printPlan(p, plan)
+}
+
+func runApply(ctx context.Context, a *app, args []string) error {
+	var autoApprove bool
+	client, plan, p, err := a.preparePlan(ctx, "apply", args, func(fs *flag.FlagSet) {
+		fs.BoolVar(&autoApprove, "auto-approve", false, "apply changes without asking for confirmation")
+	})
+	if err != nil {
+		return err
+	}
+	if err = printPlan(p, plan); err != nil {
+		return err
+	}
+	if plan.IsEmpty() {
+		return nil
+	}
+	if !autoApprove {
+		fmt.Fprint(a.stderr, "Apply these changes? Only 'yes' will be accepted: ")
+		answer, _ := bufio.NewReader(a.stdin).ReadString('\n')
+		if strings.TrimSpace(answer) != "yes" {
+			return fmt.Errorf("apply cancelled")
+		}
+	}
+
+	applied, err := plan.Apply(ctx, client)
+	fmt.Fprintf(a.stderr, "applied %d of %d changes\n", applied, len(plan.Changes))
+	return err
+}
+
+// preparePlan parses flags common for plan and apply, loads desired state and makes plan.
+// register adds flags specific for the command
+func (a *app) preparePlan(ctx context.Context, name string, args []string, register func(fs *flag.FlagSet),
+) (*accountclient.Client, *reconcile.Plan, *printer, error) {
+	var cfg clientConfig
+	fs, err := a.newFlagSet(name, "<desired state file>", &cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var organisations listValue
+	fs.Var(&organisations, "organisation-id",
+		"organisation whose accounts missing in desired state are deleted, can be repeated or comma-separated")
+	prune := fs.Bool("prune", false, "delete accounts missing in desired state from organisations of desired accounts")
+	if register != nil {
+		register(fs)
+	}
+	if err = parseFlags(fs, args, 1); err != nil {
+		return nil, nil, nil, err
+	}
+	options := reconcile.PlanOptions{Prune: *prune}
+	for _, organisation := range organisations {
+		organisationID, parseErr := uuid.Parse(organisation)
+		if parseErr != nil {
+			return nil, nil, nil, usageErr("invalid --organisation-id %q: %s", organisation, parseErr)
+		}
+		options.OrganisationIDs = append(options.OrganisationIDs, organisationID)
+	}
+
+	client, p, err := a.setup(&cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to open desired state: %w", err)
+	}
+	defer file.Close()
+	desired, err := reconcile.Load(file)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	plan, err := reconcile.NewPlan(ctx, client, desired, options)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return client, plan, p, nil
+}
+
+func printPlan(p *printer, plan *reconcile.Plan) error {
+	if p.format != formatTable {
+		return p.encode(plan)
+	}
+	return plan.Write(p.out)
+}